@@ -41,6 +41,8 @@ func (b *Bigmap[K, V]) SetContent(elt ...MapEntry[K, V]) {
 }
 
 // SetRPC defines the client to use when getting a value from the bigmap.
+// Use a pinned client (see rpc.Client.Pin) to read values from the same
+// block as the storage that contained the bigmap.
 func (b *Bigmap[K, B]) SetRPC(client RPC) *Bigmap[K, B] {
 	b.rpc = client
 	return b
@@ -0,0 +1,34 @@
+package bind
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/rpc"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func TestBigmapGetPinned(t *testing.T) {
+	hash := tezos.NewBlockHash(append(make([]byte, 31), 1))
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"int":"42"}`))
+	}))
+	defer server.Close()
+	cli, err := rpc.NewClient(server.URL, nil)
+	require.NoError(t, err)
+
+	b := NewBigmap[string, *big.Int](7)
+	b.SetRPC(cli.PinBlock(hash))
+	v, err := b.Get(context.Background(), "key")
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(42), v)
+	require.True(t, strings.HasPrefix(path, "/chains/main/blocks/"+hash.String()+"/context/big_maps/7/"), path)
+}
@@ -115,6 +115,37 @@ func (c *Contract) Reload(ctx context.Context) error {
 	return nil
 }
 
+// Pin returns a copy of the contract that reads all state from the current
+// head block. Storage is reloaded at that block and subsequent queries,
+// views and bigmap lookups use the same snapshot.
+func (c *Contract) Pin(ctx context.Context) (*Contract, error) {
+	cli, err := c.rpc.Pin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.pin(ctx, cli)
+}
+
+// PinBlock is like Pin but uses block hash as snapshot.
+func (c *Contract) PinBlock(ctx context.Context, hash tezos.BlockHash) (*Contract, error) {
+	return c.pin(ctx, c.rpc.PinBlock(hash))
+}
+
+func (c *Contract) pin(ctx context.Context, cli *rpc.Client) (*Contract, error) {
+	pinned := *c
+	pinned.rpc = cli
+	if pinned.script == nil {
+		if err := pinned.Resolve(ctx); err != nil {
+			return nil, err
+		}
+		return &pinned, nil
+	}
+	if err := pinned.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return &pinned, nil
+}
+
 func (c *Contract) ResolveMetadata(ctx context.Context) (*Tz16, error) {
 	if c.meta != nil {
 		return c.meta, nil
@@ -370,6 +401,20 @@ func (c *Contract) CallMulti(ctx context.Context, args []CallArguments, opts *rp
 	return c.rpc.Send(ctx, op, opts)
 }
 
+// CallBatch is like CallMulti, but splits large batches into multiple
+// operation groups that fit protocol limits. It returns a receipt per group.
+func (c *Contract) CallBatch(ctx context.Context, args []CallArguments, opts *rpc.CallOptions) ([]*rpc.Receipt, error) {
+	contents := make([]codec.Operation, 0, len(args))
+	for _, arg := range args {
+		if arg == nil {
+			continue
+		}
+		arg.WithDestination(c.addr)
+		contents = append(contents, arg.Encode())
+	}
+	return c.rpc.SendBatch(ctx, contents, opts)
+}
+
 func (c *Contract) Deploy(ctx context.Context, opts *rpc.CallOptions) (*rpc.Receipt, error) {
 	return c.DeployExt(ctx, tezos.Address{}, 0, opts)
 }
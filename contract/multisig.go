@@ -0,0 +1,476 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/micheline"
+	"github.com/trilitech/tzgo/rpc"
+	"github.com/trilitech/tzgo/signer"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+var (
+	ErrNotMultisig        = errors.New("contract: not a generic multisig")
+	ErrMultisigThreshold  = errors.New("contract: multisig threshold not reached")
+	ErrMultisigUnknownKey = errors.New("contract: key is not a multisig signer")
+	ErrMultisigSignature  = errors.New("contract: invalid multisig signature")
+)
+
+// Parameter and storage types of the generic multisig contract shipped
+// with octez (generic_multisig.tz).
+//
+//	parameter (or (unit %default)
+//	              (pair %main
+//	                 (pair :payload
+//	                    (nat %counter)
+//	                    (or :action
+//	                       (lambda %operation unit (list operation))
+//	                       (pair %change_keys (nat %threshold) (list %keys key))))
+//	                 (list %sigs (option signature))));
+//	storage (pair (nat %stored_counter) (pair (nat %threshold) (list %keys key)));
+var (
+	multisigActionType = micheline.NewCode(micheline.T_OR,
+		micheline.NewCodeAnno(micheline.T_LAMBDA, "%operation",
+			micheline.NewCode(micheline.T_UNIT),
+			micheline.NewCode(micheline.T_LIST, micheline.NewCode(micheline.T_OPERATION)),
+		),
+		micheline.NewPairType(
+			micheline.NewCodeAnno(micheline.T_NAT, "%threshold"),
+			micheline.NewCodeAnno(micheline.T_LIST, "%keys", micheline.NewCode(micheline.T_KEY)),
+			"%change_keys",
+		),
+	)
+
+	multisigParamType = micheline.NewCode(micheline.T_OR,
+		micheline.NewCodeAnno(micheline.T_UNIT, "%default"),
+		micheline.NewPairType(
+			micheline.NewPairType(
+				micheline.NewCodeAnno(micheline.T_NAT, "%counter"),
+				multisigActionType,
+			),
+			micheline.NewCodeAnno(micheline.T_LIST, "%sigs",
+				micheline.NewCode(micheline.T_OPTION, micheline.NewCode(micheline.T_SIGNATURE)),
+			),
+			"%main",
+		),
+	)
+
+	multisigStorageType = micheline.NewPairType(
+		micheline.NewCodeAnno(micheline.T_NAT, "%stored_counter"),
+		micheline.NewPairType(
+			micheline.NewCodeAnno(micheline.T_NAT, "%threshold"),
+			micheline.NewCodeAnno(micheline.T_LIST, "%keys", micheline.NewCode(micheline.T_KEY)),
+		),
+	)
+)
+
+// IsMultisigScript checks if script matches the parameter and storage types
+// of the octez generic multisig contract. Annotations are ignored.
+func IsMultisigScript(script *micheline.Script) bool {
+	if script == nil || !script.IsValid() {
+		return false
+	}
+	return script.ParamType().IsEqual(micheline.NewType(multisigParamType)) &&
+		script.StorageType().IsEqual(micheline.NewType(multisigStorageType))
+}
+
+func (c Contract) IsMultisig() bool {
+	return IsMultisigScript(c.script)
+}
+
+func (c *Contract) AsMultisig() *Multisig {
+	return &Multisig{
+		Address:  c.addr,
+		contract: c,
+	}
+}
+
+// Represents a generic multisig contract (octez generic_multisig.tz)
+type Multisig struct {
+	Address  tezos.Address
+	contract *Contract
+}
+
+func NewMultisig(addr tezos.Address, cli *rpc.Client) *Multisig {
+	return &Multisig{Address: addr, contract: NewContract(addr, cli)}
+}
+
+func (m Multisig) Contract() *Contract {
+	return m.contract
+}
+
+// MultisigStorage is the decoded storage of a generic multisig contract.
+type MultisigStorage struct {
+	Counter   int64       `json:"stored_counter"`
+	Threshold int64       `json:"threshold"`
+	Keys      []tezos.Key `json:"keys"`
+}
+
+// ParseMultisigStorage decodes a generic multisig storage value in either
+// readable or optimized form.
+func ParseMultisigStorage(prim micheline.Prim) (*MultisigStorage, error) {
+	args := prim.UnfoldPairRecursive(micheline.NewType(multisigStorageType))
+	if len(args) != 3 || !args[0].IsValid() || args[0].Int == nil || args[1].Int == nil {
+		return nil, fmt.Errorf("contract: invalid multisig storage %s", prim.Dump())
+	}
+	s := &MultisigStorage{
+		Counter:   args[0].Int.Int64(),
+		Threshold: args[1].Int.Int64(),
+		Keys:      make([]tezos.Key, 0, len(args[2].Args)),
+	}
+	for _, v := range args[2].Args {
+		var (
+			key tezos.Key
+			err error
+		)
+		switch v.Type {
+		case micheline.PrimString:
+			key, err = tezos.ParseKey(v.String)
+		case micheline.PrimBytes:
+			key, err = tezos.DecodeKey(v.Bytes)
+		default:
+			err = fmt.Errorf("unexpected key type %s", v.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("contract: invalid multisig key: %w", err)
+		}
+		s.Keys = append(s.Keys, key)
+	}
+	return s, nil
+}
+
+// GetStorage reloads and decodes current multisig storage.
+func (m *Multisig) GetStorage(ctx context.Context) (*MultisigStorage, error) {
+	if m.contract.script == nil {
+		if err := m.contract.Resolve(ctx); err != nil {
+			return nil, err
+		}
+	} else if err := m.contract.Reload(ctx); err != nil {
+		return nil, err
+	}
+	if !m.contract.IsMultisig() {
+		return nil, ErrNotMultisig
+	}
+	return ParseMultisigStorage(*m.contract.store)
+}
+
+// MultisigAction is the action part of a multisig payload, either a lambda
+// of type `lambda unit (list operation)` or a change of keys and threshold.
+// All contained data must be in optimized form because signatures are
+// produced over its packed representation.
+type MultisigAction struct {
+	micheline.Prim
+}
+
+// NewMultisigLambda wraps arbitrary code of type `lambda unit (list operation)`.
+func NewMultisigLambda(code micheline.Prim) MultisigAction {
+	return MultisigAction{micheline.NewCode(micheline.D_LEFT, code)}
+}
+
+// NewMultisigTransfer produces a lambda that transfers amount to an implicit
+// account or to the default entrypoint of a smart contract.
+func NewMultisigTransfer(to tezos.Address, amount tezos.N) MultisigAction {
+	if to.IsContract() {
+		return NewMultisigCall(to, amount, micheline.Parameters{
+			Entrypoint: micheline.DEFAULT,
+			Value:      micheline.NewCode(micheline.D_UNIT),
+		}, micheline.NewType(micheline.NewCode(micheline.T_UNIT)))
+	}
+	return NewMultisigLambda(micheline.NewSeq(
+		micheline.NewCode(micheline.I_DROP),
+		micheline.NewCode(micheline.I_NIL, micheline.NewCode(micheline.T_OPERATION)),
+		micheline.NewCode(micheline.I_PUSH, micheline.NewCode(micheline.T_KEY_HASH), micheline.NewKeyHash(to)),
+		micheline.NewCode(micheline.I_IMPLICIT_ACCOUNT),
+		micheline.NewCode(micheline.I_PUSH, micheline.NewCode(micheline.T_MUTEZ), micheline.NewMutez(amount)),
+		micheline.NewCode(micheline.I_UNIT),
+		micheline.NewCode(micheline.I_TRANSFER_TOKENS),
+		micheline.NewCode(micheline.I_CONS),
+	))
+}
+
+// NewMultisigCall produces a lambda that calls entrypoint params.Entrypoint of
+// smart contract to with params.Value of type typ and sends amount along.
+func NewMultisigCall(to tezos.Address, amount tezos.N, params micheline.Parameters, typ micheline.Type) MultisigAction {
+	contract := micheline.NewCode(micheline.I_CONTRACT, typ.Prim)
+	if ep := params.Entrypoint; ep != "" && ep != micheline.DEFAULT {
+		contract = micheline.NewCodeAnno(micheline.I_CONTRACT, "%"+ep, typ.Prim)
+	}
+	return NewMultisigLambda(micheline.NewSeq(
+		micheline.NewCode(micheline.I_DROP),
+		micheline.NewCode(micheline.I_NIL, micheline.NewCode(micheline.T_OPERATION)),
+		micheline.NewCode(micheline.I_PUSH, micheline.NewCode(micheline.T_ADDRESS), micheline.NewAddress(to)),
+		contract,
+		// ASSERT_SOME
+		micheline.NewCode(micheline.I_IF_NONE,
+			micheline.NewSeq(micheline.NewSeq(
+				micheline.NewCode(micheline.I_UNIT),
+				micheline.NewCode(micheline.I_FAILWITH),
+			)),
+			micheline.NewSeq(),
+		),
+		micheline.NewCode(micheline.I_PUSH, micheline.NewCode(micheline.T_MUTEZ), micheline.NewMutez(amount)),
+		micheline.NewCode(micheline.I_PUSH, typ.Prim, params.Value),
+		micheline.NewCode(micheline.I_TRANSFER_TOKENS),
+		micheline.NewCode(micheline.I_CONS),
+	))
+}
+
+// NewMultisigDelegation produces a lambda that sets the multisig's delegate.
+// Passing an invalid address withdraws the current delegate.
+func NewMultisigDelegation(delegate tezos.Address) MultisigAction {
+	var set micheline.Prim
+	if delegate.IsValid() {
+		set = micheline.NewSeq(
+			micheline.NewCode(micheline.I_PUSH, micheline.NewCode(micheline.T_KEY_HASH), micheline.NewKeyHash(delegate)),
+			micheline.NewCode(micheline.I_SOME),
+		)
+	} else {
+		set = micheline.NewCode(micheline.I_NONE, micheline.NewCode(micheline.T_KEY_HASH))
+	}
+	return NewMultisigLambda(micheline.NewSeq(
+		micheline.NewCode(micheline.I_DROP),
+		micheline.NewCode(micheline.I_NIL, micheline.NewCode(micheline.T_OPERATION)),
+		set,
+		micheline.NewCode(micheline.I_SET_DELEGATE),
+		micheline.NewCode(micheline.I_CONS),
+	))
+}
+
+// NewMultisigChangeKeys replaces the multisig's signer keys and threshold.
+func NewMultisigChangeKeys(threshold int64, keys []tezos.Key) MultisigAction {
+	list := micheline.NewSeq()
+	for _, k := range keys {
+		list.Args = append(list.Args, micheline.NewBytes(k.Bytes()))
+	}
+	return MultisigAction{micheline.NewCode(micheline.D_RIGHT,
+		micheline.NewPair(micheline.NewInt64(threshold), list),
+	)}
+}
+
+// MultisigRequest collects signatures for a single multisig action. Signatures
+// are kept in the order of the contract's signer keys.
+type MultisigRequest struct {
+	Contract   tezos.Address
+	ChainId    tezos.ChainIdHash
+	Counter    int64
+	Threshold  int64
+	Keys       []tezos.Key
+	Action     MultisigAction
+	Signatures []tezos.Signature
+}
+
+// NewRequest creates a signing request for action based on the multisig's
+// current counter, threshold and keys.
+func (m *Multisig) NewRequest(ctx context.Context, action MultisigAction) (*MultisigRequest, error) {
+	store, err := m.GetStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cli := m.contract.Client()
+	chainId := cli.ChainId
+	if !chainId.IsValid() {
+		chainId, err = cli.GetChainId(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewMultisigRequest(m.Address, chainId, store, action), nil
+}
+
+// NewMultisigRequest creates a signing request for action from known
+// contract state.
+func NewMultisigRequest(addr tezos.Address, chainId tezos.ChainIdHash, store *MultisigStorage, action MultisigAction) *MultisigRequest {
+	return &MultisigRequest{
+		Contract:   addr,
+		ChainId:    chainId,
+		Counter:    store.Counter,
+		Threshold:  store.Threshold,
+		Keys:       store.Keys,
+		Action:     action,
+		Signatures: make([]tezos.Signature, len(store.Keys)),
+	}
+}
+
+// Payload returns the payload passed to the `main` entrypoint,
+// i.e. `Pair counter action`.
+func (r MultisigRequest) Payload() micheline.Prim {
+	return micheline.NewPair(micheline.NewInt64(r.Counter), r.Action.Prim)
+}
+
+// Bytes returns the packed data signers must sign, i.e.
+// `PACK (Pair (Pair chain_id self) (Pair counter action))`.
+func (r MultisigRequest) Bytes() []byte {
+	return micheline.NewPair(
+		micheline.NewPair(
+			micheline.NewBytes(r.ChainId.Bytes()),
+			micheline.NewAddress(r.Contract),
+		),
+		r.Payload(),
+	).Pack()
+}
+
+// AddSignature verifies sig against the payload and stores it at the
+// position of key.
+func (r *MultisigRequest) AddSignature(key tezos.Key, sig tezos.Signature) error {
+	idx := -1
+	for i, k := range r.Keys {
+		if k.IsEqual(key) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return ErrMultisigUnknownKey
+	}
+	digest := tezos.Digest(r.Bytes())
+	if err := key.Verify(digest[:], sig); err != nil {
+		return fmt.Errorf("%w: %v", ErrMultisigSignature, err)
+	}
+	r.Signatures[idx] = sig
+	return nil
+}
+
+// Sign asks signer s to sign the payload for address addr.
+func (r *MultisigRequest) Sign(ctx context.Context, s signer.Signer, addr tezos.Address) error {
+	key, err := s.GetKey(ctx, addr)
+	if err != nil {
+		return err
+	}
+	sig, err := signer.SignPacked(ctx, s, addr, r.Bytes())
+	if err != nil {
+		return err
+	}
+	return r.AddSignature(key, sig)
+}
+
+// SignAll collects signatures from all addresses managed by signers
+// that match one of the multisig's keys.
+func (r *MultisigRequest) SignAll(ctx context.Context, signers ...signer.Signer) error {
+	for _, s := range signers {
+		addrs, err := s.ListAddresses(ctx)
+		if err != nil {
+			return err
+		}
+		for _, addr := range addrs {
+			if !r.HasSigner(addr) {
+				continue
+			}
+			if err := r.Sign(ctx, s, addr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// HasSigner returns true when addr belongs to one of the multisig's keys.
+func (r MultisigRequest) HasSigner(addr tezos.Address) bool {
+	for _, k := range r.Keys {
+		if k.Address().Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r MultisigRequest) NumSignatures() int {
+	var n int
+	for _, sig := range r.Signatures {
+		if sig.IsValid() {
+			n++
+		}
+	}
+	return n
+}
+
+func (r MultisigRequest) IsComplete() bool {
+	return int64(r.NumSignatures()) >= r.Threshold
+}
+
+// Check verifies all collected signatures and ensures the threshold is met.
+func (r MultisigRequest) Check() error {
+	if len(r.Signatures) != len(r.Keys) {
+		return fmt.Errorf("contract: multisig has %d keys but %d signatures", len(r.Keys), len(r.Signatures))
+	}
+	digest := tezos.Digest(r.Bytes())
+	for i, sig := range r.Signatures {
+		if !sig.IsValid() {
+			continue
+		}
+		if err := r.Keys[i].Verify(digest[:], sig); err != nil {
+			return fmt.Errorf("%w for key %s: %v", ErrMultisigSignature, r.Keys[i], err)
+		}
+	}
+	if !r.IsComplete() {
+		return fmt.Errorf("%w: %d/%d", ErrMultisigThreshold, r.NumSignatures(), r.Threshold)
+	}
+	return nil
+}
+
+// Args returns call arguments for the multisig's `main` entrypoint.
+func (r MultisigRequest) Args() *MultisigArgs {
+	args := &MultisigArgs{
+		Payload:    r.Payload(),
+		Signatures: r.Signatures,
+	}
+	args.WithDestination(r.Contract)
+	return args
+}
+
+// Execute checks the request and calls the multisig's `main` entrypoint.
+func (m *Multisig) Execute(ctx context.Context, req *MultisigRequest, opts *rpc.CallOptions) (*rpc.Receipt, error) {
+	if err := req.Check(); err != nil {
+		return nil, err
+	}
+	return m.contract.Call(ctx, req.Args(), opts)
+}
+
+type MultisigArgs struct {
+	TxArgs
+	Payload    micheline.Prim
+	Signatures []tezos.Signature
+}
+
+var _ CallArguments = (*MultisigArgs)(nil)
+
+func (a *MultisigArgs) WithSource(addr tezos.Address) CallArguments {
+	a.Source = addr.Clone()
+	return a
+}
+
+func (a *MultisigArgs) WithDestination(addr tezos.Address) CallArguments {
+	a.Destination = addr.Clone()
+	return a
+}
+
+func (a MultisigArgs) Parameters() *micheline.Parameters {
+	sigs := micheline.NewSeq()
+	for _, sig := range a.Signatures {
+		if sig.IsValid() {
+			sigs.Args = append(sigs.Args, micheline.NewOption(micheline.NewBytes(sig.Data)))
+		} else {
+			sigs.Args = append(sigs.Args, micheline.NewOption())
+		}
+	}
+	return &micheline.Parameters{
+		Entrypoint: "main",
+		Value:      micheline.NewPair(a.Payload, sigs),
+	}
+}
+
+func (a MultisigArgs) Encode() *codec.Transaction {
+	return &codec.Transaction{
+		Manager: codec.Manager{
+			Source: a.Source,
+		},
+		Destination: a.Destination,
+		Amount:      a.Amount,
+		Parameters:  a.Parameters(),
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package contract
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/trilitech/tzgo/micheline"
+	"github.com/trilitech/tzgo/signer"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func TestMultisigScriptDetection(t *testing.T) {
+	script := micheline.NewScript()
+	script.Code.Param.Args[0] = multisigParamType.CloneNoAnnots()
+	script.Code.Storage.Args[0] = multisigStorageType.CloneNoAnnots()
+	if !IsMultisigScript(script) {
+		t.Fatalf("expected generic multisig script to be detected")
+	}
+	script.Code.Storage.Args[0] = micheline.NewCode(micheline.T_NAT)
+	if IsMultisigScript(script) {
+		t.Fatalf("unexpected detection of non-multisig script")
+	}
+}
+
+func TestMultisigRequest(t *testing.T) {
+	ctx := context.Background()
+	var (
+		keys    []tezos.Key
+		signers []signer.Signer
+	)
+	for i := 0; i < 3; i++ {
+		sk, err := tezos.GenerateKey(tezos.KeyTypeEd25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, sk.Public())
+		signers = append(signers, signer.NewFromKey(sk))
+	}
+
+	// storage as returned by the node in readable mode (comb pair)
+	store := micheline.NewCode(micheline.D_PAIR,
+		micheline.NewInt64(7),
+		micheline.NewInt64(2),
+		micheline.NewSeq(
+			micheline.NewString(keys[0].String()),
+			micheline.NewString(keys[1].String()),
+			micheline.NewString(keys[2].String()),
+		),
+	)
+	store.Type = micheline.PrimVariadicAnno
+	ms, err := ParseMultisigStorage(store)
+	if err != nil {
+		t.Fatalf("parse storage: %v", err)
+	}
+	if ms.Counter != 7 || ms.Threshold != 2 || len(ms.Keys) != 3 || !ms.Keys[2].IsEqual(keys[2]) {
+		t.Fatalf("unexpected storage %#v", ms)
+	}
+
+	addr := tezos.MustParseAddress("KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn")
+	dest := tezos.MustParseAddress("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+	chain := tezos.MustParseChainIdHash("NetXdQprcVkpaWU")
+	req := NewMultisigRequest(addr, chain, ms, NewMultisigTransfer(dest, 1_000_000))
+
+	// packed payload is `Pair (Pair chain_id self) (Pair counter action)`
+	buf := req.Bytes()
+	if buf[0] != 0x05 {
+		t.Fatalf("payload is not packed: %x", buf)
+	}
+	var payload micheline.Prim
+	if err := payload.UnmarshalBinary(buf[1:]); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if got := payload.Args[0].Args[0].Bytes; string(got) != string(chain.Bytes()) {
+		t.Errorf("chain id mismatch: %x", got)
+	}
+	if got := payload.Args[0].Args[1].Bytes; string(got) != string(addr.EncodePadded()) {
+		t.Errorf("contract address mismatch: %x", got)
+	}
+	if got := payload.Args[1].Args[0].Int.Int64(); got != 7 {
+		t.Errorf("counter mismatch: %d", got)
+	}
+	if got := payload.Args[1].Args[1].OpCode; got != micheline.D_LEFT {
+		t.Errorf("expected lambda action, got %s", got)
+	}
+
+	if err := req.Check(); !errors.Is(err, ErrMultisigThreshold) {
+		t.Fatalf("expected threshold error, got %v", err)
+	}
+
+	// only the first and last signer take part
+	if err := req.SignAll(ctx, signers[0], signers[2]); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if n := req.NumSignatures(); n != 2 {
+		t.Fatalf("expected 2 signatures, got %d", n)
+	}
+	if err := req.Check(); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	// signatures from unknown keys are rejected
+	sk, _ := tezos.GenerateKey(tezos.KeyTypeEd25519)
+	digest := tezos.Digest(buf)
+	sig, _ := sk.Sign(digest[:])
+	if err := req.AddSignature(sk.Public(), sig); !errors.Is(err, ErrMultisigUnknownKey) {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+	// signatures over other data are rejected
+	if err := req.AddSignature(keys[1], sig); !errors.Is(err, ErrMultisigSignature) {
+		t.Fatalf("expected signature error, got %v", err)
+	}
+
+	params := req.Args().Parameters()
+	if params.Entrypoint != "main" {
+		t.Fatalf("unexpected entrypoint %q", params.Entrypoint)
+	}
+	sigs := params.Value.Args[1].Args
+	if len(sigs) != 3 || sigs[0].OpCode != micheline.D_SOME || sigs[1].OpCode != micheline.D_NONE || sigs[2].OpCode != micheline.D_SOME {
+		t.Fatalf("unexpected signature list %s", params.Value.Args[1].Dump())
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/micheline"
+	"github.com/trilitech/tzgo/signer"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+var (
+	ErrNoPermitCounter = errors.New("contract: permit counter not found")
+	ErrPermitSignature = errors.New("contract: invalid permit signature")
+)
+
+// Storage field names and TZIP-16 off-chain view used by common TZIP-17
+// implementations to expose the permit counter.
+var (
+	PermitCounterView   = "GetCounter"
+	PermitCounterFields = []string{"counter", "permit_counter"}
+)
+
+func (c Contract) IsPermit() bool {
+	return c.script != nil && c.script.Implements(micheline.ITzip17)
+}
+
+// GetPermitCounter reads the contract's current permit counter. It first
+// tries the TZIP-16 `GetCounter` off-chain view and then falls back to
+// well-known storage fields.
+func (c *Contract) GetPermitCounter(ctx context.Context) (int64, error) {
+	if c.script == nil {
+		if err := c.Resolve(ctx); err != nil {
+			return 0, err
+		}
+	} else if err := c.Reload(ctx); err != nil {
+		return 0, err
+	}
+	if meta, err := c.ResolveMetadata(ctx); err == nil && meta.HasView(PermitCounterView) {
+		view := meta.GetView(PermitCounterView)
+		prim, err := view.Run(ctx, c, micheline.InvalidPrim)
+		if err != nil {
+			return 0, err
+		}
+		if prim.Type != micheline.PrimInt {
+			return 0, fmt.Errorf("contract: unexpected permit counter %s", prim.Dump())
+		}
+		return prim.Int.Int64(), nil
+	}
+	store := c.StorageValue()
+	for _, name := range PermitCounterFields {
+		if n, ok := store.GetInt64(name); ok {
+			return n, nil
+		}
+	}
+	return 0, ErrNoPermitCounter
+}
+
+// PermitHash returns the TZIP-17 parameter hash, i.e. the blake2b hash
+// of the packed call parameter value. The value must be in optimized form.
+func PermitHash(params *micheline.Parameters) []byte {
+	h := tezos.Digest(params.Value.Pack())
+	return h[:]
+}
+
+// Permit is a TZIP-17 off-chain approval for a single contract call.
+type Permit struct {
+	Contract  tezos.Address
+	ChainId   tezos.ChainIdHash
+	Counter   int64
+	Call      CallArguments
+	Key       tezos.Key
+	Signature tezos.Signature
+}
+
+// NewPermit creates a permit for call on contract addr from known contract state.
+func NewPermit(addr tezos.Address, chainId tezos.ChainIdHash, counter int64, call CallArguments) *Permit {
+	call.WithDestination(addr)
+	return &Permit{
+		Contract: addr,
+		ChainId:  chainId,
+		Counter:  counter,
+		Call:     call,
+	}
+}
+
+// NewPermit creates a permit for call using the contract's current permit counter.
+func (c *Contract) NewPermit(ctx context.Context, call CallArguments) (*Permit, error) {
+	counter, err := c.GetPermitCounter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chainId := c.rpc.ChainId
+	if !chainId.IsValid() {
+		chainId, err = c.rpc.GetChainId(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewPermit(c.addr, chainId, counter, call), nil
+}
+
+// WithCounter overrides the permit counter, e.g. when submitting several
+// permits from the same signer in one batch.
+func (p *Permit) WithCounter(n int64) *Permit {
+	p.Counter = n
+	return p
+}
+
+// Hash returns the parameter hash of the permitted call.
+func (p Permit) Hash() []byte {
+	return PermitHash(p.Call.Parameters())
+}
+
+// Bytes returns the packed data a permit issuer signs, i.e.
+// `PACK (Pair (Pair self chain_id) (Pair counter param_hash))`.
+func (p Permit) Bytes() []byte {
+	return micheline.NewPair(
+		micheline.NewPair(
+			micheline.NewAddress(p.Contract),
+			micheline.NewBytes(p.ChainId.Bytes()),
+		),
+		micheline.NewPair(
+			micheline.NewInt64(p.Counter),
+			micheline.NewBytes(p.Hash()),
+		),
+	).Pack()
+}
+
+// Sign asks signer s to sign the permit for address addr.
+func (p *Permit) Sign(ctx context.Context, s signer.Signer, addr tezos.Address) error {
+	key, err := s.GetKey(ctx, addr)
+	if err != nil {
+		return err
+	}
+	sig, err := signer.SignPacked(ctx, s, addr, p.Bytes())
+	if err != nil {
+		return err
+	}
+	p.Key = key
+	p.Signature = sig
+	return p.Verify()
+}
+
+// Verify checks the permit signature against the permit issuer's key.
+func (p Permit) Verify() error {
+	if !p.Key.IsValid() || !p.Signature.IsValid() {
+		return fmt.Errorf("%w: missing key or signature", ErrPermitSignature)
+	}
+	digest := tezos.Digest(p.Bytes())
+	if err := p.Key.Verify(digest[:], p.Signature); err != nil {
+		return fmt.Errorf("%w: %v", ErrPermitSignature, err)
+	}
+	return nil
+}
+
+// Prim returns the permit list element `Pair key (Pair signature param_hash)`.
+func (p Permit) Prim() micheline.Prim {
+	return micheline.NewPair(
+		micheline.NewBytes(p.Key.Bytes()),
+		micheline.NewPair(
+			micheline.NewBytes(p.Signature.Data),
+			micheline.NewBytes(p.Hash()),
+		),
+	)
+}
+
+type PermitArgs struct {
+	TxArgs
+	Permits []*Permit
+}
+
+var _ CallArguments = (*PermitArgs)(nil)
+
+func NewPermitArgs(permits ...*Permit) *PermitArgs {
+	args := &PermitArgs{Permits: permits}
+	if len(permits) > 0 {
+		args.WithDestination(permits[0].Contract)
+	}
+	return args
+}
+
+func (a *PermitArgs) WithSource(addr tezos.Address) CallArguments {
+	a.Source = addr.Clone()
+	return a
+}
+
+func (a *PermitArgs) WithDestination(addr tezos.Address) CallArguments {
+	a.Destination = addr.Clone()
+	return a
+}
+
+func (a PermitArgs) Parameters() *micheline.Parameters {
+	list := micheline.NewSeq()
+	for _, p := range a.Permits {
+		list.Args = append(list.Args, p.Prim())
+	}
+	return &micheline.Parameters{
+		Entrypoint: "permit",
+		Value:      list,
+	}
+}
+
+func (a PermitArgs) Encode() *codec.Transaction {
+	return &codec.Transaction{
+		Manager: codec.Manager{
+			Source: a.Source,
+		},
+		Destination: a.Destination,
+		Parameters:  a.Parameters(),
+	}
+}
+
+// NewPermitOp builds a single batch operation for relayers. It submits all
+// permits, grouped into one permit call per contract, followed by the
+// permitted calls in order. Use rpc.Client.Send to sign and broadcast
+// the operation from the relayer's account.
+func NewPermitOp(permits ...*Permit) *codec.Op {
+	op := codec.NewOp()
+	groups := make(map[string]*PermitArgs)
+	order := make([]*PermitArgs, 0)
+	for _, p := range permits {
+		key := p.Contract.String()
+		args, ok := groups[key]
+		if !ok {
+			args = NewPermitArgs()
+			args.WithDestination(p.Contract)
+			groups[key] = args
+			order = append(order, args)
+		}
+		args.Permits = append(args.Permits, p)
+	}
+	for _, args := range order {
+		op.WithContents(args.Encode())
+	}
+	for _, p := range permits {
+		op.WithContents(p.Call.Encode())
+	}
+	return op
+}
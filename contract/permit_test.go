@@ -0,0 +1,72 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package contract
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/trilitech/tzgo/micheline"
+	"github.com/trilitech/tzgo/signer"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func TestPermit(t *testing.T) {
+	ctx := context.Background()
+	sk, err := tezos.GenerateKey(tezos.KeyTypeEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := sk.Address()
+	token := tezos.MustParseAddress("KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn")
+	other := tezos.MustParseAddress("KT1VYsVfmobT7rsMVivvZ4J8i3bPiqz12NaH")
+	dest := tezos.MustParseAddress("tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx")
+	chain := tezos.MustParseChainIdHash("NetXdQprcVkpaWU")
+
+	call := NewFA2TransferArgs().WithTransfer(owner, dest, tezos.NewZ(0), tezos.NewZ(100))
+	p := NewPermit(token, chain, 3, call)
+
+	// hash is blake2b over the packed parameter value
+	want := tezos.Digest(call.Parameters().Value.Pack())
+	if !bytes.Equal(p.Hash(), want[:]) {
+		t.Fatalf("param hash mismatch")
+	}
+
+	// signed data is `Pair (Pair self chain_id) (Pair counter param_hash)`
+	var data micheline.Prim
+	if err := data.UnmarshalBinary(p.Bytes()[1:]); err != nil {
+		t.Fatalf("decode permit data: %v", err)
+	}
+	if !bytes.Equal(data.Args[0].Args[0].Bytes, token.EncodePadded()) ||
+		!bytes.Equal(data.Args[0].Args[1].Bytes, chain.Bytes()) ||
+		data.Args[1].Args[0].Int.Int64() != 3 ||
+		!bytes.Equal(data.Args[1].Args[1].Bytes, want[:]) {
+		t.Fatalf("unexpected permit data %s", data.Dump())
+	}
+
+	if err := p.Verify(); !errors.Is(err, ErrPermitSignature) {
+		t.Fatalf("expected signature error on unsigned permit, got %v", err)
+	}
+	if err := p.Sign(ctx, signer.NewFromKey(sk), owner); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := p.Verify(); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	// permits for different contracts are grouped into one call each,
+	// followed by the permitted calls
+	p2 := NewPermit(token, chain, 4, NewFA2TransferArgs().WithTransfer(owner, dest, tezos.NewZ(0), tezos.NewZ(1)))
+	p3 := NewPermit(other, chain, 0, NewFA1TransferArgs().WithTransfer(owner, dest, tezos.NewZ(1)))
+	op := NewPermitOp(p, p2, p3)
+	if len(op.Contents) != 5 {
+		t.Fatalf("expected 5 operations, got %d", len(op.Contents))
+	}
+	first := NewPermitArgs(p, p2).Parameters()
+	if first.Entrypoint != "permit" || len(first.Value.Args) != 2 {
+		t.Fatalf("unexpected permit call %s", first.Value.Dump())
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+// Baker health monitor
+//
+// Watches new blocks and reports missed bakes, missed attestations, late
+// rounds and approaching deactivation for one or more bakers.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/echa/log"
+	"github.com/trilitech/tzgo/rpc"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+var (
+	flags    = flag.NewFlagSet("bakerhealth", flag.ContinueOnError)
+	verbose  bool
+	node     string
+	all      bool
+	asJson   bool
+	dal      bool
+	maxRound int
+	grace    int64
+)
+
+func init() {
+	flags.Usage = func() {}
+	flags.BoolVar(&verbose, "v", false, "be verbose")
+	flags.StringVar(&node, "node", "https://rpc.tzpro.io", "Tezos node URL")
+	flags.BoolVar(&all, "all", false, "print successful bakes and attestations too")
+	flags.BoolVar(&asJson, "json", false, "print events as JSON lines")
+	flags.BoolVar(&dal, "dal", false, "report attestations without DAL content")
+	flags.IntVar(&maxRound, "max-round", 4, "highest baking round to check")
+	flags.Int64Var(&grace, "grace", 2, "warn this many cycles before deactivation")
+}
+
+func main() {
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			fmt.Println("Usage: bakerhealth [args] <baker> [<baker> ...]")
+			fmt.Println("\nArguments")
+			flags.PrintDefaults()
+			os.Exit(0)
+		}
+		fmt.Println("Error:", err)
+		return
+	}
+
+	if err := run(); err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if flags.NArg() < 1 {
+		return fmt.Errorf("Baker address required")
+	}
+	bakers := make([]tezos.Address, 0, flags.NArg())
+	for _, v := range flags.Args() {
+		a, err := tezos.ParseAddress(v)
+		if err != nil {
+			return err
+		}
+		bakers = append(bakers, a)
+	}
+
+	switch {
+	case verbose:
+		log.SetLevel(log.LevelDebug)
+	default:
+		log.SetLevel(log.LevelWarn)
+	}
+	rpc.UseLogger(log.Log)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	c, err := rpc.NewClient(node, nil)
+	if err != nil {
+		return err
+	}
+	if err := c.Init(ctx); err != nil {
+		return err
+	}
+
+	mon := rpc.NewHealthMonitor(c, bakers...)
+	mon.MaxRound = maxRound
+	mon.ExpectDal = dal
+	mon.GraceWarning = grace
+	mon.OnEvent(func(ev *rpc.HealthEvent) {
+		if !all && !ev.Type.IsProblem() {
+			return
+		}
+		if asJson {
+			buf, _ := json.Marshal(ev)
+			fmt.Println(string(buf))
+			return
+		}
+		fmt.Println(ev.Time.Format("2006-01-02T15:04:05Z"), ev)
+	})
+	fmt.Printf("Watching %d baker(s) on %s\n", len(bakers), node)
+	return mon.Run(ctx)
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 
 	"github.com/trilitech/tzgo/internal/compose"
+	"github.com/trilitech/tzgo/rpc"
 )
 
 // 1 load yaml file
@@ -68,9 +69,19 @@ func (e *Engine) Run(ctx compose.Context, fname string) error {
 				return fmt.Errorf("%s[%d] (%s): %v", p.Name, i, task.Type, err)
 			}
 
-			// send
+			// send, batches may be split into multiple groups
 			opts.Confirmations = 0
-			rcpt, err := ctx.Send(op, opts)
+			var rcpt *rpc.Receipt
+			if task.Type == "batch" {
+				var rcpts []*rpc.Receipt
+				rcpts, err = ctx.SendBatch(op, opts)
+				for _, r := range rcpts {
+					ctx.Log.Debugf("%s group block=%d hash=%s", t.Type(), r.Height, r.Op.Hash)
+					rcpt = r
+				}
+			} else {
+				rcpt, err = ctx.Send(op, opts)
+			}
 			if err != nil {
 				switch task.OnError {
 				case ErrorModeFail:
@@ -313,6 +313,36 @@ func (c *Context) Send(op *codec.Op, opts *rpc.CallOptions) (*rpc.Receipt, error
 	return rcpt, nil
 }
 
+// SendBatch splits op into groups that fit protocol limits and sends them
+// in order. It returns one receipt per group.
+func (c *Context) SendBatch(op *codec.Op, opts *rpc.CallOptions) ([]*rpc.Receipt, error) {
+	if c.mode == RunModeSimulate {
+		groups, err := c.client.SplitBatch(c.Context, op.Contents, rpc.NewBatchLimits(c.client.Params), opts)
+		if err != nil {
+			return nil, err
+		}
+		rcpts := make([]*rpc.Receipt, 0, len(groups))
+		for _, g := range groups {
+			rcpt, err := c.Send(g, opts)
+			if err != nil {
+				return rcpts, err
+			}
+			rcpts = append(rcpts, rcpt)
+		}
+		return rcpts, nil
+	}
+	rcpts, err := c.client.SendBatch(c.Context, op.Contents, opts)
+	if err != nil {
+		return rcpts, err
+	}
+	for _, rcpt := range rcpts {
+		if !rcpt.IsSuccess() {
+			return rcpts, rcpt.Error()
+		}
+	}
+	return rcpts, nil
+}
+
 func (c *Context) SubscribeBlocks(cb rpc.ObserverCallback) (int, error) {
 	c.client.Listen()
 	id := c.client.BlockObserver.Subscribe(tezos.ZeroOpHash, cb)
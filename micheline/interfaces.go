@@ -238,6 +238,7 @@ var (
 	ITzip5       = Interface("TZIP-005")
 	ITzip7       = Interface("TZIP-007")
 	ITzip12      = Interface("TZIP-012")
+	ITzip17      = Interface("TZIP-017")
 
 	WellKnownInterfaces = []Interface{
 		IManager,
@@ -245,6 +246,7 @@ var (
 		ITzip5,
 		ITzip7,
 		ITzip12,
+		ITzip17,
 	}
 )
 
@@ -439,4 +441,26 @@ var InterfaceSpecs = map[Interface][]Prim{
 			),
 		),
 	},
+	// Tzip 17 a.k.a. permits
+	// https://gitlab.com/tzip/tzip/-/blob/master/proposals/tzip-17/tzip-17.md
+	ITzip17: {
+		// (list %permit
+		//   (pair
+		//     key
+		//     (pair
+		//       signature
+		//       bytes
+		//     )
+		//   )
+		// )
+		NewCodeAnno(T_LIST, "%permit",
+			NewPairType(
+				NewCode(T_KEY),
+				NewPairType(
+					NewCode(T_SIGNATURE),
+					NewCode(T_BYTES),
+				),
+			),
+		),
+	},
 }
@@ -0,0 +1,451 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// DefaultAccuserWindow is the number of levels an Accuser remembers signed
+// block headers and consensus operations for.
+const DefaultAccuserWindow = 128
+
+// Misbehaviour is a detected double signing by a delegate.
+type Misbehaviour struct {
+	Kind     MisbehaviourKind   `json:"kind"`
+	Delegate tezos.Address      `json:"delegate"` // invalid when only seen in mempool
+	Level    int64              `json:"level"`
+	Round    int                `json:"round"`
+	Slot     int                `json:"slot"`             // consensus operations only
+	Blocks   [2]tezos.BlockHash `json:"blocks,omitempty"` // double baking only
+	Ops      [2]tezos.OpHash    `json:"ops,omitempty"`    // double (pre)attestation only
+	Evidence codec.Operation    `json:"-"`                // denunciation, nil when unsupported
+	Injected tezos.OpHash       `json:"injected,omitempty"`
+	Err      error              `json:"-"`
+}
+
+func (m *Misbehaviour) String() string {
+	return fmt.Sprintf("double %s by %s level=%d round=%d", m.Kind, m.Delegate, m.Level, m.Round)
+}
+
+// AccuserCallback receives detected misbehaviour after a denunciation was
+// built and, unless disabled, injected.
+type AccuserCallback func(*Misbehaviour)
+
+type accuserKey struct {
+	kind  MisbehaviourKind
+	level int64
+	round int
+	id    string // baker address or consensus slot
+}
+
+type accuserBlock struct {
+	hash   tezos.BlockHash
+	header codec.BlockHeader
+	baker  tezos.Address
+}
+
+// Accuser watches block headers and consensus operations in blocks and the
+// mempool for conflicting signatures by the same delegate at the same level
+// and round. It builds double baking and double (pre)attestation evidence
+// and injects it.
+//
+// Attestations with DAL content and BLS aggregates are detected only when
+// the node reports them as plain attestations since their evidence cannot
+// be encoded yet.
+type Accuser struct {
+	c   *Client
+	cbs []AccuserCallback
+
+	// Window is the number of levels to remember signed content for.
+	Window int64
+	// DryRun disables injection of denunciations.
+	DryRun bool
+
+	mu      sync.Mutex
+	blocks  map[accuserKey]*accuserBlock
+	ops     map[accuserKey]*Operation
+	accused map[accuserKey]bool
+}
+
+func NewAccuser(c *Client) *Accuser {
+	return &Accuser{
+		c:       c,
+		Window:  DefaultAccuserWindow,
+		blocks:  make(map[accuserKey]*accuserBlock),
+		ops:     make(map[accuserKey]*Operation),
+		accused: make(map[accuserKey]bool),
+	}
+}
+
+// OnMisbehaviour registers fn to receive detected misbehaviour.
+func (a *Accuser) OnMisbehaviour(fn AccuserCallback) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cbs = append(a.cbs, fn)
+}
+
+// Run watches new heads and the mempool until ctx is canceled.
+func (a *Accuser) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		a.watchBlocks(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		a.watchMempool(ctx)
+	}()
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (a *Accuser) watchBlocks(ctx context.Context) {
+	var mon *BlockHeaderMonitor
+	defer func() {
+		if mon != nil {
+			mon.Close()
+		}
+	}()
+	for {
+		if mon == nil {
+			mon = NewBlockHeaderMonitor()
+			if err := a.c.MonitorBlockHeader(ctx, mon); err != nil {
+				mon.Close()
+				mon = nil
+				if !a.wait(ctx, 5*time.Second) {
+					return
+				}
+				continue
+			}
+		}
+		head, err := mon.Recv(ctx)
+		if err != nil {
+			mon.Close()
+			mon = nil
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		if err := a.processBlock(ctx, head.Hash); err != nil {
+			a.c.Log.Warnf("accuser: block %d: %v", head.Level, err)
+		}
+		a.prune(head.Level)
+	}
+}
+
+func (a *Accuser) watchMempool(ctx context.Context) {
+	var mon *MempoolMonitor
+	defer func() {
+		if mon != nil {
+			mon.Close()
+		}
+	}()
+	for {
+		if mon == nil {
+			mon = NewMempoolMonitor()
+			if err := a.c.MonitorMempoolAll(ctx, mon); err != nil {
+				mon.Close()
+				mon = nil
+				if !a.wait(ctx, 5*time.Second) {
+					return
+				}
+				continue
+			}
+		}
+		ops, err := mon.Recv(ctx)
+		if err != nil {
+			mon.Close()
+			mon = nil
+			if ctx.Err() != nil {
+				return
+			}
+			// the stream closes on every new head
+			if !errors.Is(err, io.EOF) && !a.wait(ctx, time.Second) {
+				return
+			}
+			continue
+		}
+		for _, op := range ops {
+			a.accuse(ctx, a.AddOperation(op))
+		}
+	}
+}
+
+func (a *Accuser) wait(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// processBlock checks the header and consensus operations of block id.
+func (a *Accuser) processBlock(ctx context.Context, id BlockID) error {
+	head, err := a.c.GetBlockHeader(ctx, id)
+	if err != nil {
+		return err
+	}
+	meta, err := a.c.GetBlockMetadata(ctx, id)
+	if err != nil {
+		return err
+	}
+	m, err := a.AddBlockHeader(head, meta.Baker)
+	if err != nil {
+		return err
+	}
+	if m != nil {
+		a.accuse(ctx, []*Misbehaviour{m})
+	}
+	ops, err := a.c.GetBlockOperationList(ctx, id, 0)
+	if err != nil {
+		return err
+	}
+	for i := range ops {
+		a.accuse(ctx, a.AddOperation(&ops[i]))
+	}
+	return nil
+}
+
+// AddBlockHeader remembers the signed header h baked by baker and returns
+// misbehaviour when baker signed a different header for the same level and
+// round before.
+func (a *Accuser) AddBlockHeader(h *BlockHeader, baker tezos.Address) (*Misbehaviour, error) {
+	ch := codecBlockHeader(h)
+	hash := h.Hash
+	if !hash.IsValid() {
+		hash = ch.Hash()
+	} else if !ch.Hash().Equal(hash) {
+		return nil, fmt.Errorf("accuser: cannot re-encode header %s", hash)
+	}
+	key := accuserKey{MisbehaviourBlock, h.Level, blockRound(*h), baker.String()}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	prev, ok := a.blocks[key]
+	if !ok {
+		a.blocks[key] = &accuserBlock{hash: hash, header: ch, baker: baker}
+		return nil, nil
+	}
+	if prev.hash.Equal(hash) || a.accused[key] {
+		return nil, nil
+	}
+	a.accused[key] = true
+
+	b1, b2 := prev.header, ch
+	h1, h2 := prev.hash, hash
+	if bytes.Compare(h1[:], h2[:]) > 0 {
+		b1, b2 = b2, b1
+		h1, h2 = h2, h1
+	}
+	return &Misbehaviour{
+		Kind:     MisbehaviourBlock,
+		Delegate: baker,
+		Level:    key.level,
+		Round:    key.round,
+		Blocks:   [2]tezos.BlockHash{h1, h2},
+		Evidence: &codec.DoubleBakingEvidence{Bh1: b1, Bh2: b2},
+	}, nil
+}
+
+// AddOperation remembers signed consensus operations in op and returns
+// misbehaviour when the same slot signed a different operation for the
+// same level and round before.
+func (a *Accuser) AddOperation(op *Operation) []*Misbehaviour {
+	if len(op.Contents) != 1 {
+		return nil
+	}
+	e, ok := op.Contents[0].(*Endorsement)
+	if !ok {
+		return nil
+	}
+	var kind MisbehaviourKind
+	switch e.Kind() {
+	case tezos.OpTypeEndorsement, tezos.OpTypeAttestation:
+		kind = MisbehaviourAttestation
+	case tezos.OpTypePreendorsement, tezos.OpTypePreattestation:
+		kind = MisbehaviourPreattestation
+	default:
+		return nil
+	}
+	key := accuserKey{kind, e.GetLevel(), e.Round, fmt.Sprint(e.Slot)}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	prev, ok := a.ops[key]
+	if !ok {
+		a.ops[key] = op
+		return nil
+	}
+	pe := prev.Contents[0].(*Endorsement)
+	if !pe.Metadata.Delegate.IsValid() && e.Metadata.Delegate.IsValid() {
+		// prefer operations with known delegate
+		a.ops[key] = op
+	}
+	if prev.Hash.Equal(op.Hash) || a.accused[key] {
+		return nil
+	}
+	if pe.PayloadHash.Equal(e.PayloadHash) && prev.Branch.Equal(op.Branch) {
+		return nil
+	}
+	a.accused[key] = true
+
+	op1, op2 := prev, op
+	if bytes.Compare(op1.Hash[:], op2.Hash[:]) > 0 {
+		op1, op2 = op2, op1
+	}
+	m := &Misbehaviour{
+		Kind:     kind,
+		Delegate: pe.Metadata.Delegate,
+		Level:    key.level,
+		Round:    key.round,
+		Slot:     e.Slot,
+		Ops:      [2]tezos.OpHash{op1.Hash, op2.Hash},
+	}
+	if !m.Delegate.IsValid() {
+		m.Delegate = e.Metadata.Delegate
+	}
+	if kind == MisbehaviourAttestation {
+		m.Evidence = &codec.TenderbakeDoubleEndorsementEvidence{
+			Op1: codec.TenderbakeInlinedEndorsement{
+				Branch:      op1.Branch,
+				Endorsement: codecEndorsement(op1),
+				Signature:   op1.Signature,
+			},
+			Op2: codec.TenderbakeInlinedEndorsement{
+				Branch:      op2.Branch,
+				Endorsement: codecEndorsement(op2),
+				Signature:   op2.Signature,
+			},
+		}
+	} else {
+		e1, e2 := codecEndorsement(op1), codecEndorsement(op2)
+		m.Evidence = &codec.TenderbakeDoublePreendorsementEvidence{
+			Op1: codec.TenderbakeInlinedPreendorsement{
+				Branch:      op1.Branch,
+				Endorsement: codec.TenderbakePreendorsement(e1),
+				Signature:   op1.Signature,
+			},
+			Op2: codec.TenderbakeInlinedPreendorsement{
+				Branch:      op2.Branch,
+				Endorsement: codec.TenderbakePreendorsement(e2),
+				Signature:   op2.Signature,
+			},
+		}
+	}
+	return []*Misbehaviour{m}
+}
+
+// prune forgets content older than Window levels below level.
+func (a *Accuser) prune(level int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	min := level - a.Window
+	for k := range a.blocks {
+		if k.level < min {
+			delete(a.blocks, k)
+		}
+	}
+	for k := range a.ops {
+		if k.level < min {
+			delete(a.ops, k)
+		}
+	}
+	for k := range a.accused {
+		if k.level < min {
+			delete(a.accused, k)
+		}
+	}
+}
+
+// accuse injects evidence for each misbehaviour and signals callbacks.
+func (a *Accuser) accuse(ctx context.Context, list []*Misbehaviour) {
+	if len(list) == 0 {
+		return
+	}
+	for _, m := range list {
+		a.c.Log.Infof("accuser: %s", m)
+		if a.DryRun || m.Evidence == nil {
+			continue
+		}
+		m.Injected, m.Err = a.Inject(ctx, m.Evidence)
+		if m.Err != nil {
+			a.c.Log.Warnf("accuser: inject %s: %v", m, m.Err)
+		}
+	}
+	a.mu.Lock()
+	cbs := a.cbs
+	a.mu.Unlock()
+	for _, m := range list {
+		for _, fn := range cbs {
+			fn(m)
+		}
+	}
+}
+
+// Inject broadcasts evidence as an anonymous operation. Anonymous operations
+// do not require a valid signature.
+func (a *Accuser) Inject(ctx context.Context, evidence codec.Operation) (tezos.OpHash, error) {
+	branch, err := a.c.GetBlockHash(ctx, Head)
+	if err != nil {
+		return tezos.OpHash{}, err
+	}
+	op := codec.NewOp().
+		WithParams(a.c.Params).
+		WithBranch(branch).
+		WithContents(evidence).
+		WithSignature(tezos.ZeroSignature)
+	return a.c.Broadcast(ctx, op)
+}
+
+// codecBlockHeader converts a signed RPC block header into its codec form.
+func codecBlockHeader(h *BlockHeader) codec.BlockHeader {
+	ch := codec.BlockHeader{
+		Level:            int32(h.Level),
+		Proto:            byte(h.Proto),
+		Predecessor:      h.Predecessor,
+		Timestamp:        h.Timestamp,
+		ValidationPass:   byte(h.ValidationPass),
+		OperationsHash:   h.OperationsHash,
+		Fitness:          h.Fitness,
+		Context:          h.Context,
+		PayloadHash:      h.PayloadHash,
+		PayloadRound:     h.PayloadRound,
+		ProofOfWorkNonce: h.ProofOfWorkNonce,
+		LbVote:           h.LbVote(),
+		AiVote:           tezos.FeatureVoteOn, // encodes as zero bits when absent
+		Signature:        h.Signature,
+	}
+	if h.SeedNonceHash != nil {
+		ch.SeedNonceHash = *h.SeedNonceHash
+	}
+	if v := h.AiVote(); v != nil {
+		ch.AiVote = *v
+	}
+	return ch
+}
+
+// codecEndorsement converts a signed RPC consensus operation into its codec
+// form.
+func codecEndorsement(op *Operation) codec.TenderbakeEndorsement {
+	e := op.Contents[0].(*Endorsement)
+	return codec.TenderbakeEndorsement{
+		Slot:             int16(e.Slot),
+		Level:            int32(e.GetLevel()),
+		Round:            int32(e.Round),
+		BlockPayloadHash: e.PayloadHash,
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func accuserRandom() []byte {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	return buf
+}
+
+func accuserHeader(t *testing.T, key tezos.PrivateKey, level int64, round uint32) *BlockHeader {
+	t.Helper()
+	fit := make([]byte, 4)
+	fit[3] = byte(round)
+	h := codec.BlockHeader{
+		Level:            int32(level),
+		Proto:            2,
+		Predecessor:      tezos.MustParseBlockHash("BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2"),
+		Timestamp:        time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		ValidationPass:   4,
+		Fitness:          []tezos.HexBytes{{2}, {0, 0, 0, byte(level)}, {}, {0xff, 0xff, 0xff, 0xff}, fit},
+		PayloadHash:      tezos.NewPayloadHash(accuserRandom()),
+		ProofOfWorkNonce: tezos.HexBytes{1, 2, 3, 4, 5, 6, 7, 8},
+		LbVote:           tezos.FeatureVotePass,
+		AiVote:           tezos.FeatureVoteOn,
+	}
+	h.WithChainId(tezos.Mainnet)
+	require.NoError(t, h.Sign(key))
+	h.ChainId = nil
+	return &BlockHeader{
+		Level:                     level,
+		Proto:                     int(h.Proto),
+		Predecessor:               h.Predecessor,
+		Timestamp:                 h.Timestamp,
+		ValidationPass:            int(h.ValidationPass),
+		Fitness:                   h.Fitness,
+		PayloadHash:               h.PayloadHash,
+		ProofOfWorkNonce:          h.ProofOfWorkNonce,
+		LiquidityBakingToggleVote: h.LbVote,
+		Signature:                 h.Signature,
+		Hash:                      h.Hash(),
+	}
+}
+
+func accuserAttestation(kind tezos.OpType, slot int, payload tezos.PayloadHash) *Operation {
+	return &Operation{
+		Hash:   tezos.NewOpHash(accuserRandom()),
+		Branch: tezos.MustParseBlockHash("BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2"),
+		Contents: OperationList{&Endorsement{
+			Generic:     Generic{OpKind: kind},
+			Level:       10,
+			Round:       1,
+			Slot:        slot,
+			PayloadHash: payload,
+		}},
+		Signature: tezos.ZeroSignature,
+	}
+}
+
+func TestAccuserBlocks(t *testing.T) {
+	key, err := tezos.GenerateKey(tezos.KeyTypeEd25519)
+	require.NoError(t, err)
+	baker := key.Address()
+	a := NewAccuser(nil)
+
+	h1 := accuserHeader(t, key, 10, 0)
+	m, err := a.AddBlockHeader(h1, baker)
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	// same block again, other round, other baker
+	m, _ = a.AddBlockHeader(h1, baker)
+	assert.Nil(t, m)
+	m, _ = a.AddBlockHeader(accuserHeader(t, key, 10, 1), baker)
+	assert.Nil(t, m)
+	m, _ = a.AddBlockHeader(accuserHeader(t, key, 10, 0), tezos.MustParseAddress("tz1SUgyRB8T5jXgXAwS33pgRHAKrafyg87Yc"))
+	assert.Nil(t, m)
+
+	// conflicting header
+	h2 := accuserHeader(t, key, 10, 0)
+	m, err = a.AddBlockHeader(h2, baker)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	assert.Equal(t, MisbehaviourBlock, m.Kind)
+	assert.Equal(t, baker, m.Delegate)
+	assert.Equal(t, int64(10), m.Level)
+	assert.Equal(t, 0, m.Round)
+	assert.True(t, bytes.Compare(m.Blocks[0][:], m.Blocks[1][:]) < 0)
+	ev, ok := m.Evidence.(*codec.DoubleBakingEvidence)
+	require.True(t, ok)
+	assert.Equal(t, m.Blocks[0], ev.Bh1.Hash())
+	assert.Equal(t, m.Blocks[1], ev.Bh2.Hash())
+
+	// accused only once
+	m, _ = a.AddBlockHeader(accuserHeader(t, key, 10, 0), baker)
+	assert.Nil(t, m)
+
+	// headers that cannot be re-encoded are rejected
+	h3 := accuserHeader(t, key, 11, 0)
+	h3.Hash = h1.Hash
+	_, err = a.AddBlockHeader(h3, baker)
+	assert.Error(t, err)
+
+	// old levels are forgotten
+	a.prune(10 + a.Window + 1)
+	assert.Empty(t, a.blocks)
+	assert.Empty(t, a.accused)
+}
+
+func TestAccuserOperations(t *testing.T) {
+	a := NewAccuser(nil)
+	p1 := tezos.NewPayloadHash(accuserRandom())
+	p2 := tezos.NewPayloadHash(accuserRandom())
+
+	assert.Nil(t, a.AddOperation(accuserAttestation(tezos.OpTypeAttestation, 3, p1)))
+	assert.Nil(t, a.AddOperation(accuserAttestation(tezos.OpTypeAttestation, 4, p2)))
+	assert.Nil(t, a.AddOperation(accuserAttestation(tezos.OpTypePreattestation, 3, p2)))
+	assert.Nil(t, a.AddOperation(accuserAttestation(tezos.OpTypeAttestationWithDal, 3, p2)))
+
+	// same content seen again under a different hash is no misbehaviour
+	assert.Nil(t, a.AddOperation(accuserAttestation(tezos.OpTypeAttestation, 3, p1)))
+
+	// conflicting attestation, delegate is known from block metadata
+	op := accuserAttestation(tezos.OpTypeAttestation, 3, p2)
+	delegate := tezos.MustParseAddress("tz1R664EP6wjcM1RSUVJ7nrJisTpBW9QyJzP")
+	op.Contents[0].(*Endorsement).Metadata.Delegate = delegate
+	list := a.AddOperation(op)
+	require.Len(t, list, 1)
+	m := list[0]
+	assert.Equal(t, MisbehaviourAttestation, m.Kind)
+	assert.Equal(t, delegate, m.Delegate)
+	assert.Equal(t, int64(10), m.Level)
+	assert.Equal(t, 1, m.Round)
+	assert.Equal(t, 3, m.Slot)
+	assert.True(t, bytes.Compare(m.Ops[0][:], m.Ops[1][:]) < 0)
+	ev, ok := m.Evidence.(*codec.TenderbakeDoubleEndorsementEvidence)
+	require.True(t, ok)
+	assert.Equal(t, int16(3), ev.Op1.Endorsement.Slot)
+	assert.NotEqual(t, ev.Op1.Endorsement.BlockPayloadHash, ev.Op2.Endorsement.BlockPayloadHash)
+	assert.Nil(t, a.AddOperation(accuserAttestation(tezos.OpTypeAttestation, 3, tezos.NewPayloadHash(accuserRandom()))))
+
+	// conflicting preattestation
+	list = a.AddOperation(accuserAttestation(tezos.OpTypePreattestation, 3, p1))
+	require.Len(t, list, 1)
+	assert.Equal(t, MisbehaviourPreattestation, list[0].Kind)
+	_, ok = list[0].Evidence.(*codec.TenderbakeDoublePreendorsementEvidence)
+	assert.True(t, ok)
+
+	// evidence encodes as an anonymous operation
+	enc := codec.NewOp().
+		WithBranch(tezos.MustParseBlockHash("BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2")).
+		WithContents(m.Evidence).
+		WithSignature(tezos.ZeroSignature)
+	assert.NotEmpty(t, enc.Bytes())
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+const (
+	// batchOverhead reserves space for branch, signature and a reveal
+	batchOverhead = 32 + 96 + 180
+	// batchSlack reserves space per content for fee and limit growth
+	batchSlack = 16
+)
+
+// BatchLimits define the resource limits for a single operation group.
+type BatchLimits struct {
+	MaxGas     int64 // max total gas per group
+	MaxOpGas   int64 // max gas per content
+	MaxStorage int64 // max storage per content
+	MaxSize    int   // max encoded size per group in bytes
+	MaxOps     int   // optional max number of contents per group
+}
+
+// NewBatchLimits returns group limits for protocol params p.
+func NewBatchLimits(p *tezos.Params) BatchLimits {
+	if p == nil {
+		p = tezos.DefaultParams
+	}
+	return BatchLimits{
+		MaxGas:     p.HardGasLimitPerBlock,
+		MaxOpGas:   p.HardGasLimitPerOperation,
+		MaxStorage: p.HardStorageLimitPerOperation,
+		MaxSize:    p.MaxOperationDataLength,
+	}
+}
+
+// SplitBatch simulates contents and packs them in order into the fewest
+// operation groups that fit into limits. Estimated gas and storage limits
+// are set on all contents. Counters are left for Send to assign.
+func (c *Client) SplitBatch(ctx context.Context, contents []codec.Operation, limits BatchLimits, opts *CallOptions) ([]*codec.Op, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	_, _, key, err := c.sender(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	costs, err := c.estimateBatch(ctx, key, contents, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.packBatch(contents, costs, limits, key.Address(), opts)
+}
+
+// packBatch greedily packs contents in order into groups using their
+// estimated costs.
+func (c *Client) packBatch(contents []codec.Operation, costs []tezos.Limits, limits BatchLimits, source tezos.Address, opts *CallOptions) ([]*codec.Op, error) {
+	var (
+		groups []*codec.Op
+		op     *codec.Op
+		gas    int64
+		size   int
+	)
+	for i, v := range contents {
+		l := costs[i]
+		if !opts.IgnoreLimits {
+			l.GasLimit += opts.ExtraGasMargin
+			v.WithLimits(tezos.Limits{
+				Fee:          v.Limits().Fee,
+				GasLimit:     l.GasLimit,
+				StorageLimit: l.StorageLimit,
+			})
+		}
+		if limits.MaxOpGas > 0 && l.GasLimit > limits.MaxOpGas {
+			return nil, fmt.Errorf("batch[%d]: gas %d exceeds operation limit %d", i, l.GasLimit, limits.MaxOpGas)
+		}
+		if limits.MaxStorage > 0 && l.StorageLimit > limits.MaxStorage {
+			return nil, fmt.Errorf("batch[%d]: storage %d exceeds operation limit %d", i, l.StorageLimit, limits.MaxStorage)
+		}
+		buf := bytes.NewBuffer(nil)
+		if err := v.EncodeBuffer(buf, c.Params); err != nil {
+			return nil, fmt.Errorf("batch[%d]: %v", i, err)
+		}
+		sz := buf.Len() + batchSlack
+		if limits.MaxSize > 0 && sz+batchOverhead > limits.MaxSize {
+			return nil, fmt.Errorf("batch[%d]: size %d exceeds operation limit %d", i, sz, limits.MaxSize)
+		}
+
+		// start a new group when the content does not fit
+		if op != nil {
+			full := limits.MaxOps > 0 && len(op.Contents) >= limits.MaxOps
+			full = full || (limits.MaxGas > 0 && gas+l.GasLimit > limits.MaxGas)
+			full = full || (limits.MaxSize > 0 && size+sz+batchOverhead > limits.MaxSize)
+			if full {
+				op = nil
+			}
+		}
+		if op == nil {
+			op = codec.NewOp().WithTTL(opts.TTL).WithParams(c.Params).WithSource(source)
+			groups = append(groups, op)
+			gas, size = 0, 0
+		}
+		op.WithContents(v)
+		gas += l.GasLimit
+		size += sz
+	}
+	return groups, nil
+}
+
+// estimateBatch simulates contents and returns their costs as limits.
+// Chunks that fail to simulate as a whole are split in halves until a
+// single failing content is found.
+func (c *Client) estimateBatch(ctx context.Context, key tezos.Key, contents []codec.Operation, opts *CallOptions) ([]tezos.Limits, error) {
+	if len(contents) == 0 {
+		return nil, nil
+	}
+
+	// caller limits are restored after simulation, counters are reset
+	saved := make([]tezos.Limits, len(contents))
+	for i, v := range contents {
+		saved[i] = v.Limits()
+	}
+	restore := func() {
+		for i, v := range contents {
+			v.WithLimits(saved[i])
+			if v.GetCounter() >= 0 {
+				v.WithCounter(0)
+			}
+		}
+	}
+	op := codec.NewOp().WithTTL(opts.TTL).WithParams(c.Params)
+	op.Contents = append([]codec.Operation(nil), contents...)
+	op.WithSource(key.Address())
+	restore()
+	err := c.Complete(ctx, op, key)
+	var sim *Receipt
+	if err == nil {
+		sim, err = c.Simulate(ctx, op, opts)
+	}
+	restore()
+	if err == nil {
+		// skip an auto-added reveal
+		costs := sim.MinLimits()
+		return costs[len(costs)-len(contents):], nil
+	}
+	if len(contents) == 1 {
+		return nil, err
+	}
+
+	n := len(contents) / 2
+	head, err := c.estimateBatch(ctx, key, contents[:n], opts)
+	if err != nil {
+		return nil, err
+	}
+	tail, err := c.estimateBatch(ctx, key, contents[n:], opts)
+	if err != nil {
+		return nil, err
+	}
+	return append(head, tail...), nil
+}
+
+// SendBatch splits contents into operation groups that fit protocol limits
+// and sends each group through Send. Groups are sent in order, pipelined
+// when a CounterManager is configured and one after another otherwise.
+// It returns one receipt per group, which may be partial on error.
+func (c *Client) SendBatch(ctx context.Context, contents []codec.Operation, opts *CallOptions) ([]*Receipt, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	groups, err := c.SplitBatch(ctx, contents, NewBatchLimits(c.Params), opts)
+	if err != nil {
+		return nil, err
+	}
+	rcpts := make([]*Receipt, 0, len(groups))
+	if c.Counters == nil {
+		for i, op := range groups {
+			rcpt, err := c.Send(ctx, op, opts)
+			if err != nil {
+				return rcpts, fmt.Errorf("batch group %d: %w", i, err)
+			}
+			rcpts = append(rcpts, rcpt)
+		}
+		return rcpts, nil
+	}
+
+	// pipeline all groups, then collect receipts in order
+	var sendErr error
+	results := make([]*Result, 0, len(groups))
+	for i, op := range groups {
+		res, err := c.SendAsync(ctx, op, opts)
+		if err != nil {
+			sendErr = fmt.Errorf("batch group %d: %w", i, err)
+			break
+		}
+		results = append(results, res)
+	}
+	for i, res := range results {
+		res.WaitContext(ctx)
+		if err := res.Err(); err != nil {
+			return rcpts, fmt.Errorf("batch group %d: %w", i, err)
+		}
+		rcpt, err := res.GetReceipt(ctx)
+		if err != nil {
+			return rcpts, fmt.Errorf("batch group %d: %w", i, err)
+		}
+		rcpts = append(rcpts, rcpt)
+	}
+	return rcpts, sendErr
+}
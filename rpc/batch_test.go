@@ -0,0 +1,66 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func TestPackBatch(t *testing.T) {
+	c, err := NewClient("http://localhost", nil)
+	require.NoError(t, err)
+	c.Params = tezos.DefaultParams
+	src := tezos.MustParseAddress("tz1UBZUkXpKGhYsP5KtzDNqLLchwF4uHrGjw")
+
+	var (
+		contents []codec.Operation
+		costs    []tezos.Limits
+	)
+	for i := 0; i < 10; i++ {
+		op := codec.NewOp().WithTransfer(tezos.BurnAddress, int64(i+1))
+		contents = append(contents, op.Contents[0])
+		costs = append(costs, tezos.Limits{GasLimit: 400_000, StorageLimit: 100})
+	}
+	opts := NewCallOptions()
+	opts.ExtraGasMargin = 0
+	limits := BatchLimits{MaxGas: 1_000_000, MaxOpGas: 500_000, MaxStorage: 60000, MaxSize: 32768}
+
+	// gas bound: 2 contents per group
+	groups, err := c.packBatch(contents, costs, limits, src, opts)
+	require.NoError(t, err)
+	require.Len(t, groups, 5)
+	for i, g := range groups {
+		require.Len(t, g.Contents, 2)
+		// order is preserved
+		assert.Equal(t, contents[2*i], g.Contents[0])
+		assert.Equal(t, int64(400_000), g.Contents[0].Limits().GasLimit)
+	}
+
+	// count and size bound
+	for i := range costs {
+		costs[i].GasLimit = 1000
+	}
+	limits.MaxOps = 4
+	groups, err = c.packBatch(contents, costs, limits, src, opts)
+	require.NoError(t, err)
+	assert.Len(t, groups, 3)
+	limits.MaxOps = 0
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, contents[0].EncodeBuffer(buf, c.Params))
+	limits.MaxSize = batchOverhead + 3*(batchSlack+buf.Len())
+	groups, err = c.packBatch(contents, costs, limits, src, opts)
+	require.NoError(t, err)
+	assert.Len(t, groups, 4)
+
+	// single contents above operation limits fail
+	costs[3].GasLimit = 600_000
+	_, err = c.packBatch(contents, costs, limits, src, opts)
+	assert.Error(t, err)
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// CacheStore stores raw RPC responses by key. Implementations must be safe
+// for concurrent use.
+type CacheStore interface {
+	Get(key string) ([]byte, bool)
+	Put(key string, val []byte) error
+}
+
+// CacheStats are hit and miss counters of a ResponseCache.
+type CacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// HitRate returns the share of lookups served from cache.
+func (s CacheStats) HitRate() float64 {
+	if n := s.Hits + s.Misses; n > 0 {
+		return float64(s.Hits) / float64(n)
+	}
+	return 0
+}
+
+// ResponseCache caches responses of immutable RPC queries. Only GET requests
+// for blocks addressed by hash (or hash offset) or by a final level are
+// cached, never requests for head. Final levels are known from the client's
+// block observer, so without a running observer only hash-addressed
+// queries are cached.
+//
+// Stores are checked in order and earlier stores are filled from later
+// ones on hit, e.g. an in-memory LRU in front of a disk store.
+type ResponseCache struct {
+	stores []CacheStore
+	hits   int64
+	misses int64
+}
+
+func NewResponseCache(stores ...CacheStore) *ResponseCache {
+	return &ResponseCache{stores: stores}
+}
+
+// Get returns a cached response for key.
+func (r *ResponseCache) Get(key string) ([]byte, bool) {
+	for i, s := range r.stores {
+		val, ok := s.Get(key)
+		if !ok {
+			continue
+		}
+		for _, prev := range r.stores[:i] {
+			_ = prev.Put(key, val)
+		}
+		atomic.AddInt64(&r.hits, 1)
+		return val, true
+	}
+	atomic.AddInt64(&r.misses, 1)
+	return nil, false
+}
+
+// Put stores a response in all stores.
+func (r *ResponseCache) Put(key string, val []byte) error {
+	var err error
+	for _, s := range r.stores {
+		if e := s.Put(key, val); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Stats returns cache hit and miss counters.
+func (r *ResponseCache) Stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadInt64(&r.hits),
+		Misses: atomic.LoadInt64(&r.misses),
+	}
+}
+
+// cacheKey returns a cache key for urlpath when the response is immutable.
+func (c *Client) cacheKey(urlpath string) (string, bool) {
+	const prefix = "chains/main/blocks/"
+	path := strings.TrimPrefix(c.pinPath(urlpath), "/")
+	if !strings.HasPrefix(path, prefix) {
+		return "", false
+	}
+	id := path[len(prefix):]
+	if n := strings.IndexAny(id, "/?"); n >= 0 {
+		id = id[:n]
+	}
+	base, ofs, hasOfs := strings.Cut(id, "~")
+	if hasOfs {
+		if _, err := strconv.ParseUint(ofs, 10, 64); err != nil {
+			return "", false
+		}
+	}
+	if _, err := tezos.ParseBlockHash(base); err != nil {
+		// levels are immutable once final
+		level, err := strconv.ParseInt(base, 10, 64)
+		if err != nil || hasOfs || c.BlockObserver == nil || level > c.BlockObserver.finalLevel() {
+			return "", false
+		}
+	}
+	// levels differ between networks
+	scope := c.BaseURL.String()
+	if c.ChainId.IsValid() {
+		scope = c.ChainId.String()
+	}
+	return scope + "/" + path, true
+}
+
+// getCached serves a GET request from cache or fetches and caches it.
+func (c *Client) getCached(ctx context.Context, key, urlpath string, result interface{}) error {
+	if buf, ok := c.Cache.Get(key); ok {
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(buf, result)
+	}
+	req, err := c.NewRequest(ctx, http.MethodGet, urlpath, nil)
+	if err != nil {
+		return err
+	}
+	var raw json.RawMessage
+	if err := c.Do(req, &raw); err != nil {
+		return err
+	}
+	if len(raw) > 0 {
+		if err := c.Cache.Put(key, raw); err != nil {
+			c.Log.Warnf("rpc: cache store failed: %v", err)
+		}
+	}
+	if result == nil || len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, result)
+}
+
+// LRUCache is an in-memory CacheStore that evicts the least recently used
+// responses when the total size exceeds a byte budget.
+type LRUCache struct {
+	mu    sync.Mutex
+	max   int64
+	size  int64
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key string
+	val []byte
+}
+
+// NewLRUCache returns an in-memory cache holding up to maxBytes of responses.
+func NewLRUCache(maxBytes int64) *LRUCache {
+	return &LRUCache{
+		max:   maxBytes,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (l *LRUCache) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	l.ll.MoveToFront(e)
+	return e.Value.(*lruEntry).val, true
+}
+
+func (l *LRUCache) Put(key string, val []byte) error {
+	sz := int64(len(key) + len(val))
+	if sz > l.max {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.items[key]; ok {
+		l.ll.MoveToFront(e)
+		return nil
+	}
+	l.items[key] = l.ll.PushFront(&lruEntry{key, val})
+	l.size += sz
+	for l.size > l.max {
+		e := l.ll.Back()
+		ent := e.Value.(*lruEntry)
+		l.ll.Remove(e)
+		delete(l.items, ent.key)
+		l.size -= int64(len(ent.key) + len(ent.val))
+	}
+	return nil
+}
+
+// Len returns the number of cached responses.
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ll.Len()
+}
+
+// Size returns the total size of cached responses in bytes.
+func (l *LRUCache) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+// DiskCache is a CacheStore that keeps one file per response in a
+// directory. It never evicts entries.
+type DiskCache struct {
+	dir string
+}
+
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:]))
+}
+
+func (d *DiskCache) Get(key string) ([]byte, bool) {
+	buf, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return buf, true
+}
+
+func (d *DiskCache) Put(key string, val []byte) error {
+	tmp, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(val); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), d.path(key))
+}
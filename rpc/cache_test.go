@@ -0,0 +1,116 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func TestCacheKey(t *testing.T) {
+	hash := tezos.NewBlockHash(append(make([]byte, 31), 1))
+	c, err := NewClient("http://localhost:8732", nil)
+	require.NoError(t, err)
+	c.BlockObserver.head = &BlockHeaderLogEntry{Level: 100}
+	for path, want := range map[string]bool{
+		"chains/main/blocks/head/header":                     false,
+		"chains/main/blocks/head~10/header":                  false,
+		"chains/main/blocks/" + hash.String():                true,
+		"chains/main/blocks/" + hash.String() + "/header":    true,
+		"chains/main/blocks/" + hash.String() + "~2/header":  true,
+		"chains/main/blocks/" + hash.String() + "~x/header":  false,
+		"chains/main/blocks/98/context/big_maps/1?x=1":       true,
+		"chains/main/blocks/99/header":                       false,
+		"chains/main/blocks/90~1/header":                     false,
+		"chains/main/mempool/pending_operations":             false,
+		"monitor/heads/main":                                 false,
+		"/chains/main/blocks/" + hash.String() + "/metadata": true,
+	} {
+		_, ok := c.cacheKey(path)
+		assert.Equal(t, want, ok, path)
+	}
+
+	// pinned head is cacheable
+	_, ok := c.PinBlock(hash).cacheKey("chains/main/blocks/head/header")
+	assert.True(t, ok)
+}
+
+func TestResponseCache(t *testing.T) {
+	hash := tezos.NewBlockHash(append(make([]byte, 31), 1))
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"hash": hash, "level": 42})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	disk, err := NewDiskCache(dir)
+	require.NoError(t, err)
+	c, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+	mem := NewLRUCache(1 << 20)
+	c.WithCache(mem, disk)
+
+	for i := 0; i < 3; i++ {
+		h, err := c.GetBlockHeader(ctx, hash)
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), h.Level)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1}, c.Cache.Stats())
+	assert.InDelta(t, 0.666, c.Cache.Stats().HitRate(), 0.001)
+
+	// head is never cached
+	for i := 0; i < 2; i++ {
+		_, err := c.GetBlockHeader(ctx, Head)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1}, c.Cache.Stats())
+
+	// a new client is served from disk and fills its memory cache
+	c2, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+	mem2 := NewLRUCache(1 << 20)
+	c2.WithCache(mem2, disk)
+	_, err = c2.GetBlockHeader(ctx, hash)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, 1, mem2.Len())
+}
+
+func TestLRUCache(t *testing.T) {
+	l := NewLRUCache(30)
+	require.NoError(t, l.Put("a", make([]byte, 9)))
+	require.NoError(t, l.Put("b", make([]byte, 9)))
+	require.NoError(t, l.Put("c", make([]byte, 9)))
+	assert.Equal(t, 3, l.Len())
+	assert.Equal(t, int64(30), l.Size())
+
+	// touch a, then evict the least recently used b
+	_, ok := l.Get("a")
+	require.True(t, ok)
+	require.NoError(t, l.Put("d", make([]byte, 9)))
+	_, ok = l.Get("b")
+	assert.False(t, ok)
+	_, ok = l.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 3, l.Len())
+
+	// oversized values are skipped
+	require.NoError(t, l.Put("e", make([]byte, 40)))
+	_, ok = l.Get("e")
+	assert.False(t, ok)
+}
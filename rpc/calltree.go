@@ -0,0 +1,370 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/trilitech/tzgo/micheline"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// CallNode is a single external or internal operation in a call tree.
+type CallNode struct {
+	Kind        tezos.OpType           // transaction, origination, delegation, ...
+	Source      tezos.Address          // caller
+	Destination tezos.Address          // callee, originated contract or new delegate
+	Entrypoint  string                 // called entrypoint (transactions only)
+	Amount      int64                  // transferred or originated amount in mutez
+	Parameters  *micheline.Parameters  // call parameters as sent
+	Params      *micheline.Value       // typed call parameters, set by Decode
+	Nonce       int64                  // internal operation nonce, -1 for external
+	Status      tezos.OpStatus         // result status
+	Errors      []OperationError       // result errors
+	GasUsed     int64                  // gas consumed by this node only
+	Storage     *micheline.Prim        // new storage of the callee
+	BigmapDiff  micheline.BigmapEvents // bigmap updates made by the callee
+	Events      []*Event               // events emitted by the callee
+	Children    []*CallNode            // operations emitted by the callee
+}
+
+// IsInternal returns true when the node was emitted by a contract.
+func (n *CallNode) IsInternal() bool {
+	return n.Nonce >= 0
+}
+
+// IsSuccess returns true when the node was applied.
+func (n *CallNode) IsSuccess() bool {
+	return n.Status == tezos.OpStatusApplied
+}
+
+// TotalGas returns the gas consumed by this node and all its descendants.
+func (n *CallNode) TotalGas() int64 {
+	gas := n.GasUsed
+	for _, c := range n.Children {
+		gas += c.TotalGas()
+	}
+	return gas
+}
+
+// Walk calls fn for n and all descendants in execution order with the
+// nesting depth of each node. Walk stops when fn returns false.
+func (n *CallNode) Walk(fn func(node *CallNode, depth int) bool) {
+	n.walk(fn, 0)
+}
+
+func (n *CallNode) walk(fn func(*CallNode, int) bool, depth int) bool {
+	if !fn(n, depth) {
+		return false
+	}
+	for _, c := range n.Children {
+		if !c.walk(fn, depth+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// Decode maps call parameters to typed values using script, the script of
+// the callee. It is a no-op for nodes without parameters.
+func (n *CallNode) Decode(script *micheline.Script) error {
+	if n.Parameters == nil || script == nil {
+		return nil
+	}
+	ep, prim, err := n.Parameters.MapEntrypoint(script.ParamType())
+	if err != nil {
+		return err
+	}
+	n.Params = micheline.NewValuePtr(ep.Type(), prim)
+	return nil
+}
+
+// CallTree is the execution tree of all contents in an operation.
+type CallTree []*CallNode
+
+// CallTree reconstructs the call tree of all contents in operation o. There
+// is one root per content in o.Contents.
+//
+// Receipts list internal operations flat in execution order. Because the
+// protocol executes internal operations depth-first, each operation is
+// attached to the nearest caller on the current call path whose callee is
+// its source. Nonces disambiguate re-entrant calls into the same contract.
+func (o Operation) CallTree() CallTree {
+	tree := make(CallTree, 0, len(o.Contents))
+	for _, op := range o.Contents {
+		tree = append(tree, buildCallTree(op))
+	}
+	return tree
+}
+
+// CallTree returns the call tree of the operation in r, e.g. a simulation
+// result.
+func (r *Receipt) CallTree() CallTree {
+	if r.Op == nil {
+		return nil
+	}
+	return r.Op.CallTree()
+}
+
+func buildCallTree(op TypedOperation) *CallNode {
+	res := op.Result()
+	root := &CallNode{
+		Kind:       op.Kind(),
+		Nonce:      -1,
+		Status:     res.Status,
+		Errors:     res.Errors,
+		GasUsed:    res.Gas(),
+		Storage:    res.Storage,
+		BigmapDiff: res.BigmapEvents(),
+	}
+	switch v := op.(type) {
+	case *Transaction:
+		root.Source = v.Source
+		root.Destination = v.Destination
+		root.Amount = v.Amount
+		root.Parameters = v.Parameters
+	case *Origination:
+		root.Source = v.Source
+		root.Amount = v.Balance
+	case *Delegation:
+		root.Source = v.Source
+		root.Destination = v.Delegate
+	case *Reveal:
+		root.Source = v.Source
+	}
+	if root.Kind == tezos.OpTypeOrigination && len(res.OriginatedContracts) > 0 {
+		root.Destination = res.OriginatedContracts[0]
+	}
+	if root.Parameters != nil {
+		root.Entrypoint = root.Parameters.Entrypoint
+	}
+
+	// current call path, the root is always the outermost caller
+	path := []*CallNode{root}
+	for _, in := range op.Meta().InternalResults {
+		parent := findCaller(path, in.Source, in.Nonce)
+		if parent == nil {
+			// unknown caller, keep the operation visible at the root
+			parent = root
+		}
+		// drop completed calls from the path
+		for path[len(path)-1] != parent {
+			path = path[:len(path)-1]
+		}
+		if e, ok := in.Event(); ok {
+			parent.Events = append(parent.Events, e)
+			continue
+		}
+		node := newCallNode(in)
+		parent.Children = append(parent.Children, node)
+		if node.Kind == tezos.OpTypeTransaction {
+			path = append(path, node)
+		}
+	}
+	return root
+}
+
+// findCaller returns the innermost node on path that called source. When
+// source appears more than once, the node whose last emitted operation
+// directly precedes nonce is preferred.
+func findCaller(path []*CallNode, source tezos.Address, nonce int64) *CallNode {
+	var match *CallNode
+	for i := len(path) - 1; i >= 0; i-- {
+		n := path[i]
+		if !n.Destination.Equal(source) {
+			continue
+		}
+		if match == nil {
+			match = n
+		}
+		if last := n.lastNonce(); last < 0 || last+1 == nonce {
+			return n
+		}
+	}
+	return match
+}
+
+// lastNonce returns the nonce of the last operation emitted by n or -1.
+func (n *CallNode) lastNonce() int64 {
+	last := int64(-1)
+	if l := len(n.Children); l > 0 {
+		last = n.Children[l-1].Nonce
+	}
+	if l := len(n.Events); l > 0 && n.Events[l-1].Nonce > last {
+		last = n.Events[l-1].Nonce
+	}
+	return last
+}
+
+func newCallNode(in *InternalResult) *CallNode {
+	node := &CallNode{
+		Kind:       in.Kind,
+		Source:     in.Source,
+		Nonce:      in.Nonce,
+		Status:     in.Result.Status,
+		Errors:     in.Result.Errors,
+		GasUsed:    in.Result.Gas(),
+		Storage:    in.Result.Storage,
+		BigmapDiff: in.Result.BigmapEvents(),
+		Parameters: in.Parameters,
+	}
+	switch in.Kind {
+	case tezos.OpTypeTransaction:
+		node.Amount = in.Amount
+		if in.Destination != nil {
+			node.Destination = *in.Destination
+		}
+	case tezos.OpTypeOrigination:
+		node.Amount = in.Balance
+		if len(in.Result.OriginatedContracts) > 0 {
+			node.Destination = in.Result.OriginatedContracts[0]
+		}
+	case tezos.OpTypeDelegation:
+		if in.Delegate != nil {
+			node.Destination = *in.Delegate
+		}
+	}
+	if node.Parameters != nil {
+		node.Entrypoint = node.Parameters.Entrypoint
+	}
+	return node
+}
+
+// Walk calls fn for every node in execution order.
+func (t CallTree) Walk(fn func(node *CallNode, depth int) bool) {
+	for _, n := range t {
+		if !n.walk(fn, 0) {
+			return
+		}
+	}
+}
+
+// TotalGas returns the gas consumed by all nodes.
+func (t CallTree) TotalGas() int64 {
+	var gas int64
+	for _, n := range t {
+		gas += n.TotalGas()
+	}
+	return gas
+}
+
+// Decode loads the scripts of all called contracts and maps call parameters
+// to typed values. Scripts are fetched once per contract.
+func (t CallTree) Decode(ctx context.Context, c *Client) error {
+	scripts := make(map[string]*micheline.Script)
+	var err error
+	t.Walk(func(n *CallNode, _ int) bool {
+		if n.Parameters == nil || !n.Destination.IsContract() {
+			return true
+		}
+		key := n.Destination.String()
+		script, ok := scripts[key]
+		if !ok {
+			script, err = c.GetContractScript(ctx, n.Destination)
+			if err != nil {
+				err = fmt.Errorf("calltree: script %s: %w", n.Destination, err)
+				return false
+			}
+			scripts[key] = script
+		}
+		if err = n.Decode(script); err != nil {
+			err = fmt.Errorf("calltree: %s %%%s: %w", n.Destination, n.Entrypoint, err)
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+// String renders the call tree as indented text for debugging.
+func (t CallTree) String() string {
+	var b strings.Builder
+	t.Render(&b)
+	return b.String()
+}
+
+// Render writes the call tree as indented text to w.
+func (t CallTree) Render(w io.Writer) {
+	for _, n := range t {
+		n.render(w, "", "")
+	}
+}
+
+// String renders n and its descendants as indented text.
+func (n *CallNode) String() string {
+	var b strings.Builder
+	n.render(&b, "", "")
+	return b.String()
+}
+
+const callTreeValueLimit = 256
+
+func (n *CallNode) render(w io.Writer, head, indent string) {
+	fmt.Fprintf(w, "%s%s %s", head, n.Kind, n.Source)
+	if n.Destination.IsValid() {
+		fmt.Fprintf(w, " -> %s", n.Destination)
+	}
+	if n.Entrypoint != "" {
+		fmt.Fprintf(w, " %%%s", n.Entrypoint)
+	}
+	if n.Amount != 0 {
+		fmt.Fprintf(w, " amount=%d", n.Amount)
+	}
+	fmt.Fprintf(w, " gas=%d %s", n.GasUsed, n.Status)
+	if n.IsInternal() {
+		fmt.Fprintf(w, " nonce=%d", n.Nonce)
+	}
+	io.WriteString(w, "\n")
+
+	// details use the child indent, with a vertical bar when children follow
+	detail := indent + "   "
+	if len(n.Children) > 0 {
+		detail = indent + "│  "
+	}
+	switch {
+	case n.Params != nil:
+		fmt.Fprintf(w, "%sparams: %s\n", detail, oneLine(n.Params.Dump()))
+	case n.Parameters != nil:
+		fmt.Fprintf(w, "%sparams: %s\n", detail, n.Parameters.Value.DumpLimit(callTreeValueLimit))
+	}
+	if n.Storage != nil {
+		fmt.Fprintf(w, "%sstorage: %s\n", detail, n.Storage.DumpLimit(callTreeValueLimit))
+	}
+	for _, e := range n.BigmapDiff {
+		switch e.Action {
+		case micheline.DiffActionUpdate:
+			fmt.Fprintf(w, "%sbigmap %d %s %s = %s\n", detail, e.Id, e.Action, e.Key.DumpLimit(callTreeValueLimit), e.Value.DumpLimit(callTreeValueLimit))
+		case micheline.DiffActionRemove:
+			fmt.Fprintf(w, "%sbigmap %d %s %s\n", detail, e.Id, e.Action, e.Key.DumpLimit(callTreeValueLimit))
+		case micheline.DiffActionCopy:
+			fmt.Fprintf(w, "%sbigmap %d %s from %d\n", detail, e.DestId, e.Action, e.SourceId)
+		default:
+			fmt.Fprintf(w, "%sbigmap %d %s\n", detail, e.Id, e.Action)
+		}
+	}
+	for _, e := range n.Events {
+		fmt.Fprintf(w, "%sevent %%%s %s nonce=%d\n", detail, e.Tag, e.Payload.DumpLimit(callTreeValueLimit), e.Nonce)
+	}
+	for _, e := range n.Errors {
+		fmt.Fprintf(w, "%serror: %s\n", detail, e.GenericError.Error())
+	}
+	for i, c := range n.Children {
+		if i == len(n.Children)-1 {
+			c.render(w, indent+"└─ ", indent+"   ")
+		} else {
+			c.render(w, indent+"├─ ", indent+"│  ")
+		}
+	}
+}
+
+func oneLine(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > callTreeValueLimit {
+		s = s[:callTreeValueLimit] + "..."
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/micheline"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+const (
+	treeUser = "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"
+	treeA    = "KT18x7skHqt9hGYjrg3EJKceigfz1sJJPgZ8"
+	treeB    = "KT19BqYoEU1dVRsVWcWQXneherXR4mLpf4LZ"
+	treeC    = "KT19at7rQUvyjxnZ2fBv7D9zc8rkyG7gAoU8"
+	treeD    = "KT1AEfeckNbdEYwaMKkytBwPJPycz7jdSGea"
+)
+
+func treeInternal(src, dst string, nonce int, gas string) string {
+	return `{"kind":"transaction","source":"` + src + `","nonce":` + strconv.Itoa(nonce) +
+		`,"amount":"0","destination":"` + dst + `","parameters":{"entrypoint":"default","value":{"prim":"Unit"}},` +
+		`"result":{"status":"applied","consumed_milligas":"` + gas + `"}}`
+}
+
+func decodeTreeOp(t *testing.T, internals ...string) *Operation {
+	t.Helper()
+	raw := `{"hash":"oo6JPEAy8VuMRGaFuMmLNFFGdJgiaKfnmT1CpHJfKP3Ye5ZahiP","contents":[{
+		"kind":"transaction","source":"` + treeUser + `","fee":"1000","counter":"1","gas_limit":"10000","storage_limit":"0",
+		"amount":"1500000","destination":"` + treeA + `",
+		"parameters":{"entrypoint":"deposit","value":{"int":"42"}},
+		"metadata":{"balance_updates":[],"operation_result":{"status":"applied","consumed_milligas":"2500000",
+			"storage":{"int":"42"},
+			"lazy_storage_diff":[{"kind":"big_map","id":"7","diff":{"action":"update","updates":[
+				{"key_hash":"expruPBWMccKybChcJmGF8oMo263Ri6HgbKbAJRS8j6GbmqZJPJfVG","key":{"int":"1"},"value":{"int":"2"}}]}}]},
+		"internal_operation_results":[` + strings.Join(internals, ",") + `]}}]}`
+	op := &Operation{}
+	decodeTestJSON(t, raw, op)
+	return op
+}
+
+func TestCallTree(t *testing.T) {
+	// A emits n0 (to B) and n1 (to C), B emits n2 (to D), C emits event n3;
+	// receipts list them depth-first as n0, n2, n1, n3
+	op := decodeTreeOp(t,
+		treeInternal(treeA, treeB, 0, "1000000"),
+		treeInternal(treeB, treeD, 2, "500000"),
+		treeInternal(treeA, treeC, 1, "700000"),
+		`{"kind":"event","source":"`+treeC+`","nonce":3,"type":{"prim":"nat"},"tag":"paid","payload":{"int":"7"},
+			"result":{"status":"applied","consumed_milligas":"100000"}}`,
+	)
+	tree := op.CallTree()
+	require.Len(t, tree, 1)
+	root := tree[0]
+	assert.Equal(t, tezos.OpTypeTransaction, root.Kind)
+	assert.Equal(t, treeUser, root.Source.String())
+	assert.Equal(t, treeA, root.Destination.String())
+	assert.Equal(t, "deposit", root.Entrypoint)
+	assert.Equal(t, int64(1500000), root.Amount)
+	assert.Equal(t, int64(-1), root.Nonce)
+	assert.False(t, root.IsInternal())
+	assert.True(t, root.IsSuccess())
+	assert.Equal(t, int64(2500), root.GasUsed)
+	require.NotNil(t, root.Storage)
+	require.Len(t, root.BigmapDiff, 1)
+	assert.Equal(t, int64(7), root.BigmapDiff[0].Id)
+
+	require.Len(t, root.Children, 2)
+	b, c := root.Children[0], root.Children[1]
+	assert.Equal(t, treeB, b.Destination.String())
+	assert.Equal(t, treeC, c.Destination.String())
+	assert.Equal(t, int64(1), c.Nonce)
+	require.Len(t, b.Children, 1)
+	assert.Equal(t, treeD, b.Children[0].Destination.String())
+	assert.Empty(t, c.Children)
+	require.Len(t, c.Events, 1)
+	assert.Equal(t, "paid", c.Events[0].Tag)
+	assert.Equal(t, int64(7), c.Events[0].Payload.Int.Int64())
+
+	assert.Equal(t, int64(2500+1000+500+700), tree.TotalGas())
+
+	var order []string
+	tree.Walk(func(n *CallNode, depth int) bool {
+		order = append(order, strings.Repeat(" ", depth)+n.Destination.String()[:5])
+		return true
+	})
+	assert.Equal(t, []string{"KT18x", " KT19B", "  KT1AE", " KT19a"}, order)
+
+	s := tree.String()
+	assert.Contains(t, s, "transaction "+treeUser+" -> "+treeA+" %deposit amount=1500000 gas=2500 applied\n")
+	assert.Contains(t, s, "├─ transaction "+treeA+" -> "+treeB)
+	assert.Contains(t, s, "│  └─ transaction "+treeB+" -> "+treeD)
+	assert.Contains(t, s, "└─ transaction "+treeA+" -> "+treeC)
+	assert.Contains(t, s, "event %paid {\"int\":\"7\"} nonce=3")
+	assert.Contains(t, s, "bigmap 7 update")
+}
+
+func TestCallTreeReentrant(t *testing.T) {
+	// A emits n0 (to B) and n1 (to C), B calls back into A with n2 and the
+	// inner A emits n3 (to D); execution order is n0, n2, n3, n1
+	op := decodeTreeOp(t,
+		treeInternal(treeA, treeB, 0, "1000"),
+		treeInternal(treeB, treeA, 2, "1000"),
+		treeInternal(treeA, treeD, 3, "1000"),
+		treeInternal(treeA, treeC, 1, "1000"),
+	)
+	root := op.CallTree()[0]
+	require.Len(t, root.Children, 2)
+	assert.Equal(t, treeB, root.Children[0].Destination.String())
+	assert.Equal(t, treeC, root.Children[1].Destination.String())
+	inner := root.Children[0].Children
+	require.Len(t, inner, 1)
+	assert.Equal(t, treeA, inner[0].Destination.String())
+	require.Len(t, inner[0].Children, 1)
+	assert.Equal(t, treeD, inner[0].Children[0].Destination.String())
+}
+
+func TestCallTreeDecode(t *testing.T) {
+	var script micheline.Script
+	require.NoError(t, json.Unmarshal([]byte(`{"code":[
+		{"prim":"parameter","args":[{"prim":"or","args":[{"prim":"nat","annots":["%deposit"]},{"prim":"unit","annots":["%withdraw"]}]}]},
+		{"prim":"storage","args":[{"prim":"nat"}]},
+		{"prim":"code","args":[[]]}],"storage":{"int":"0"}}`), &script))
+
+	op := decodeTreeOp(t)
+	root := op.CallTree()[0]
+	require.NoError(t, root.Decode(&script))
+	require.NotNil(t, root.Params)
+	m, err := root.Params.Map()
+	require.NoError(t, err)
+	require.IsType(t, map[string]interface{}{}, m)
+	assert.Equal(t, "42", m.(map[string]interface{})["deposit"].(tezos.Z).String())
+	assert.Contains(t, root.String(), "params: ")
+}
@@ -56,8 +56,24 @@ type Client struct {
 	// Close connections. This may help with EOF errors from unexpected
 	// connection close by Tezos RPC.
 	CloseConns bool
+	// Retry is an optional policy for retrying failed idempotent requests.
+	// Retries are disabled when nil.
+	Retry *RetryPolicy
+	// Limiter is an optional client-side rate limiter applied to all requests.
+	Limiter *RateLimiter
+	// Counters is an optional manager that assigns account counters locally
+	// for concurrent sends. Counters are fetched from chain when nil.
+	Counters *CounterManager
+	// Journal optionally records every signed operation before injection
+	// so that it can be tracked again after a restart.
+	Journal *Journal
+	// Cache optionally caches responses of immutable block queries.
+	Cache *ResponseCache
 	// Log is the logger implementation used by this client
 	Log log.Logger
+
+	// pinned is an optional block hash that replaces head in requests
+	pinned tezos.BlockHash
 }
 
 // NewClient returns a new Tezos RPC client.
@@ -99,6 +115,41 @@ func (c *Client) Init(ctx context.Context) error {
 	return c.ResolveChainConfig(ctx)
 }
 
+// WithRetry enables retries of failed idempotent requests using policy p.
+func (c *Client) WithRetry(p *RetryPolicy) *Client {
+	c.Retry = p
+	return c
+}
+
+// WithRateLimit limits the client to rate requests per second on average
+// with bursts of up to burst requests.
+func (c *Client) WithRateLimit(rate float64, burst int) *Client {
+	c.Limiter = NewRateLimiter(rate, burst)
+	return c
+}
+
+// WithCounterManager enables local counter management for concurrent sends
+// from the same source.
+func (c *Client) WithCounterManager(serialize bool) *Client {
+	c.Counters = NewCounterManager(c)
+	c.Counters.Serialize = serialize
+	return c
+}
+
+// WithJournal records sent operations in store. Use Journal.Resume after
+// a restart to continue tracking pending operations.
+func (c *Client) WithJournal(store JournalStore) *Client {
+	c.Journal = NewJournal(c, store)
+	return c
+}
+
+// WithCache caches responses of immutable block queries in stores, e.g.
+// an LRUCache optionally followed by a DiskCache.
+func (c *Client) WithCache(stores ...CacheStore) *Client {
+	c.Cache = NewResponseCache(stores...)
+	return c
+}
+
 func (c *Client) UseIpfsUrl(uri string) error {
 	u, err := url.Parse(uri)
 	if err != nil {
@@ -138,6 +189,11 @@ func (c *Client) ResolveChainConfig(ctx context.Context) error {
 }
 
 func (c *Client) Get(ctx context.Context, urlpath string, result interface{}) error {
+	if c.Cache != nil {
+		if key, ok := c.cacheKey(urlpath); ok {
+			return c.getCached(ctx, key, urlpath, result)
+		}
+	}
 	req, err := c.NewRequest(ctx, http.MethodGet, urlpath, nil)
 	if err != nil {
 		return err
@@ -171,7 +227,7 @@ func (c *Client) Post(ctx context.Context, urlpath string, body, result interfac
 
 // NewRequest creates a Tezos RPC request.
 func (c *Client) NewRequest(ctx context.Context, method, urlStr string, body interface{}) (*http.Request, error) {
-	rel, err := url.Parse(urlStr)
+	rel, err := url.Parse(c.pinPath(urlStr))
 	if err != nil {
 		return nil, err
 	}
@@ -255,7 +311,7 @@ func (c *Client) handleResponseMonitor(ctx context.Context, resp *http.Response,
 
 // Do retrieves values from the API and marshals them into the provided interface.
 func (c *Client) Do(req *http.Request, v interface{}) error {
-	resp, err := c.client.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
 		if e, ok := err.(*url.Error); ok {
 			return e.Err
@@ -291,7 +347,7 @@ func (c *Client) Do(req *http.Request, v interface{}) error {
 // DoAsync retrieves values from the API and sends responses using the provided monitor.
 func (c *Client) DoAsync(req *http.Request, mon Monitor) error {
 	//nolint:bodyclose
-	resp, err := c.client.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
 		if e, ok := err.(*url.Error); ok {
 			return e.Err
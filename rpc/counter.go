@@ -0,0 +1,219 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// CounterManager assigns manager operation counters locally so that many
+// operations from the same source can be in flight at the same time. The
+// manager syncs an account's counter from the chain on first use and after
+// a failure that leaves the local state in doubt.
+//
+// When Serialize is set, Client.Send waits for each operation of a source to
+// be included before the next one is sent. Otherwise operations are
+// pipelined and may share a block.
+type CounterManager struct {
+	Serialize bool
+
+	c        *Client
+	mu       sync.Mutex
+	accounts map[tezos.Address]*accountCounter
+}
+
+type accountCounter struct {
+	send     sync.Mutex     // held while a serialised send is in flight
+	sync     sync.Mutex     // held while syncing from chain
+	next     int64          // next counter to assign, 0 when unknown
+	revealed bool           // account is or will be revealed
+	reveal   int64          // counter of a pending reveal, 0 when none
+	pending  map[int64]bool // assigned counters not yet included
+}
+
+func NewCounterManager(c *Client) *CounterManager {
+	return &CounterManager{
+		c:        c,
+		accounts: make(map[tezos.Address]*accountCounter),
+	}
+}
+
+func (m *CounterManager) account(addr tezos.Address) *accountCounter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	acc, ok := m.accounts[addr]
+	if !ok {
+		acc = &accountCounter{pending: make(map[int64]bool)}
+		m.accounts[addr] = acc
+	}
+	return acc
+}
+
+// Lock serialises sends for addr when Serialize is enabled and returns
+// a function that releases the lock.
+func (m *CounterManager) Lock(addr tezos.Address) func() {
+	if !m.Serialize {
+		return func() {}
+	}
+	acc := m.account(addr)
+	acc.send.Lock()
+	return acc.send.Unlock
+}
+
+// Assign sets counters on all manager operations in op and drops a reveal
+// when the account is already revealed or a reveal is pending. It returns
+// the first assigned counter.
+func (m *CounterManager) Assign(ctx context.Context, addr tezos.Address, op *codec.Op) (int64, error) {
+	acc := m.account(addr)
+	if err := m.sync(ctx, addr, acc); err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	revealed := acc.revealed
+	m.mu.Unlock()
+
+	// strip duplicate reveals
+	if revealed && len(op.Contents) > 0 && op.Contents[0].Kind() == tezos.OpTypeReveal {
+		op.Contents = op.Contents[1:]
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	first := acc.next
+	for _, v := range op.Contents {
+		// skip non-manager ops
+		if v.GetCounter() < 0 {
+			continue
+		}
+		if v.Kind() == tezos.OpTypeReveal {
+			acc.revealed = true
+			acc.reveal = acc.next
+		}
+		v.WithCounter(acc.next)
+		acc.pending[acc.next] = true
+		acc.next++
+	}
+	return first, nil
+}
+
+// sync loads the account counter from chain unless it is known.
+func (m *CounterManager) sync(ctx context.Context, addr tezos.Address, acc *accountCounter) error {
+	acc.sync.Lock()
+	defer acc.sync.Unlock()
+	m.mu.Lock()
+	known := acc.next > 0
+	m.mu.Unlock()
+	if known {
+		return nil
+	}
+	state, err := m.c.GetContractExt(ctx, addr, Head)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if acc.next == 0 {
+		acc.next = state.Counter + 1
+		acc.revealed = state.IsRevealed()
+	}
+	return nil
+}
+
+// Confirm marks counters up to and including counter as included.
+func (m *CounterManager) Confirm(addr tezos.Address, counter int64) {
+	acc := m.account(addr)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for n := range acc.pending {
+		if n <= counter {
+			delete(acc.pending, n)
+		}
+	}
+	if acc.reveal <= counter {
+		acc.reveal = 0
+	}
+}
+
+// Fail releases counter after an operation using it was not injected,
+// refused or expired. The counter is only reused when no later counter is
+// outstanding. Otherwise later counters may belong to operations that are
+// still in flight and the manager re-syncs from chain on the next Assign.
+func (m *CounterManager) Fail(addr tezos.Address, counter int64) {
+	m.fail(addr, counter, counter)
+}
+
+// fail releases counters first to last of a failed operation.
+func (m *CounterManager) fail(addr tezos.Address, first, last int64) {
+	acc := m.account(addr)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !acc.pending[first] {
+		return
+	}
+	for n := range acc.pending {
+		if n > last {
+			acc.reset()
+			return
+		}
+	}
+	for n := first; n <= last; n++ {
+		delete(acc.pending, n)
+	}
+	acc.next = first
+	if acc.reveal >= first {
+		acc.revealed = false
+		acc.reveal = 0
+	}
+}
+
+// Reset forgets local state for addr. The next Assign syncs from chain.
+func (m *CounterManager) Reset(addr tezos.Address) {
+	acc := m.account(addr)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	acc.reset()
+}
+
+func (acc *accountCounter) reset() {
+	acc.next = 0
+	acc.revealed = false
+	acc.reveal = 0
+	acc.pending = make(map[int64]bool)
+}
+
+// Pending returns the number of assigned counters for addr that are not yet
+// confirmed.
+func (m *CounterManager) Pending(addr tezos.Address) int {
+	acc := m.account(addr)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(acc.pending)
+}
+
+// release updates counter state after a send attempt ended with err.
+func (m *CounterManager) release(addr tezos.Address, first int64, last int64, err error) {
+	var berr *BroadcastError
+	switch {
+	case err == nil:
+		m.Confirm(addr, last)
+	case errors.Is(err, ErrCanceled), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		// the operation may still be included, keep counters reserved
+	case errors.As(err, &berr) && !berr.IsRefused():
+		// the injection outcome is unknown, keep counters reserved
+	case isCounterError(err):
+		m.Reset(addr)
+	default:
+		m.fail(addr, first, last)
+	}
+}
+
+func isCounterError(err error) bool {
+	var ce *CounterError
+	return errors.As(err, &ce)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func TestCounterManager(t *testing.T) {
+	ctx := context.Background()
+	key, err := tezos.GenerateKey(tezos.KeyTypeEd25519)
+	require.NoError(t, err)
+	addr := key.Address()
+
+	var (
+		counter int64 = 41
+		syncs   int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&syncs, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"counter":"%d","manager":%q}`, atomic.LoadInt64(&counter), key.Public().String())
+	}))
+	t.Cleanup(server.Close)
+	c, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+	m := c.WithCounterManager(false).Counters
+
+	newOp := func(n int) *codec.Op {
+		op := codec.NewOp().WithSource(addr)
+		op.WithContentsFront(&codec.Reveal{PublicKey: key.Public()})
+		for i := 0; i < n; i++ {
+			op.WithTransfer(addr, 1)
+		}
+		return op
+	}
+
+	// concurrent assignments never share counters
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[int64]bool)
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			op := newOp(2)
+			_, err := m.Assign(ctx, addr, op)
+			require.NoError(t, err)
+			// revealed accounts never get a second reveal
+			assert.Len(t, op.Contents, 2)
+			mu.Lock()
+			defer mu.Unlock()
+			for _, v := range op.Contents {
+				assert.False(t, seen[v.GetCounter()])
+				seen[v.GetCounter()] = true
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 20)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&syncs))
+	assert.Equal(t, 20, m.Pending(addr))
+
+	// confirm the first 4 ops, fail the 5th while later ops are in flight
+	m.Confirm(addr, 49)
+	assert.Equal(t, 12, m.Pending(addr))
+	atomic.StoreInt64(&counter, 55)
+	m.release(addr, 50, 51, errors.New("refused"))
+	assert.Equal(t, 0, m.Pending(addr))
+	first, err := m.Assign(ctx, addr, newOp(1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(56), first)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&syncs))
+
+	// the highest outstanding counter is reused
+	m.release(addr, 56, 56, errors.New("refused"))
+	assert.Equal(t, 0, m.Pending(addr))
+	first, err = m.Assign(ctx, addr, newOp(1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(56), first)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&syncs))
+
+	// counter errors resync from chain
+	atomic.StoreInt64(&counter, 60)
+	m.release(addr, 56, 56, refusedError{GenericError{ID: "proto.alpha.contract.counter_in_the_past", Kind: "branch"}})
+	first, err = m.Assign(ctx, addr, newOp(1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(61), first)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&syncs))
+
+	// unknown broadcast outcomes keep counters reserved
+	m.release(addr, 61, 61, &BroadcastError{Err: errors.New("connection reset")})
+	assert.Equal(t, 1, m.Pending(addr))
+}
@@ -5,6 +5,7 @@ package rpc
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/trilitech/tzgo/micheline"
@@ -40,6 +41,8 @@ type GenericError struct {
 	ID   string         `json:"id"`
 	Kind string         `json:"kind"`
 	With micheline.Prim `json:"with"`
+
+	raw json.RawMessage // original JSON for decoding typed errors
 }
 
 func (e GenericError) Error() string {
@@ -86,15 +89,19 @@ type Errors []Error
 
 // UnmarshalJSON implements json.Unmarshaler
 func (e *Errors) UnmarshalJSON(data []byte) error {
-	var errs []*GenericError
+	var errs []json.RawMessage
 
 	if err := json.Unmarshal(data, &errs); err != nil {
 		return err
 	}
 
 	*e = make(Errors, len(errs))
-	for i, g := range errs {
-		// TODO: handle different kinds
+	for i, buf := range errs {
+		g := &GenericError{}
+		if err := json.Unmarshal(buf, g); err != nil {
+			return err
+		}
+		g.raw = buf
 		(*e)[i] = g
 	}
 
@@ -124,6 +131,17 @@ func (e Errors) ErrorKind() string {
 	return e[0].ErrorKind()
 }
 
+// As finds the first error in e that matches target. Use it with errors.As
+// to access typed protocol errors like *CounterError.
+func (e Errors) As(target interface{}) bool {
+	for _, v := range e {
+		if errors.As(v, target) {
+			return true
+		}
+	}
+	return false
+}
+
 type httpError struct {
 	request    string
 	status     string
@@ -172,6 +190,10 @@ func (e *rpcError) Errors() []Error {
 	return e.errors
 }
 
+func (e *rpcError) As(target interface{}) bool {
+	return e.errors.As(target)
+}
+
 type plainError struct {
 	*httpError
 	msg string
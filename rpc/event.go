@@ -0,0 +1,153 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+
+	"github.com/trilitech/tzgo/micheline"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// Event is a contract event emitted with the Michelson EMIT instruction.
+// Events are reported as internal results of the calling operation.
+type Event struct {
+	Source  tezos.Address  `json:"source"`        // emitting contract
+	Tag     string         `json:"tag,omitempty"` // optional event tag
+	Type    micheline.Prim `json:"type"`          // declared payload type
+	Payload micheline.Prim `json:"payload"`       // payload value
+	Nonce   int64          `json:"nonce"`
+	Status  tezos.OpStatus `json:"status"` // status of the emitting operation
+
+	// position of the emitting operation, block fields are set for block events
+	Block   tezos.BlockHash `json:"block,omitempty"`
+	Height  int64           `json:"height,omitempty"`
+	OpHash  tezos.OpHash    `json:"op_hash"`
+	List    int             `json:"list"`
+	Pos     int             `json:"pos"`
+	Content int             `json:"content"` // index into operation contents
+}
+
+// Value returns the payload as typed value using the declared event type.
+func (e *Event) Value() micheline.Value {
+	return micheline.NewValue(micheline.NewType(e.Type), e.Payload)
+}
+
+// Map decodes the payload into Go types, see micheline.Value.Map.
+func (e *Event) Map() (interface{}, error) {
+	v := e.Value()
+	return v.Map()
+}
+
+// Unmarshal decodes the payload into val, see micheline.Value.Unmarshal.
+func (e *Event) Unmarshal(val interface{}) error {
+	v := e.Value()
+	return v.Unmarshal(val)
+}
+
+// IsSuccess returns true when the event was emitted by an applied operation.
+// Events of failed or backtracked operations have no effect.
+func (e *Event) IsSuccess() bool {
+	return e.Status == tezos.OpStatusApplied
+}
+
+// Event returns the event carried by an event internal result.
+func (r InternalResult) Event() (*Event, bool) {
+	if r.Kind != tezos.OpTypeEvent {
+		return nil, false
+	}
+	return &Event{
+		Source:  r.Source,
+		Tag:     r.Tag,
+		Type:    r.Type,
+		Payload: r.Payload,
+		Nonce:   r.Nonce,
+		Status:  r.Result.Status,
+	}, true
+}
+
+// EventFilter selects events by emitting contract and tag. Empty lists
+// match all events. Events of failed operations are skipped unless All
+// is set.
+type EventFilter struct {
+	Sources []tezos.Address
+	Tags    []string
+	All     bool
+}
+
+// Match returns true when e passes the filter.
+func (f EventFilter) Match(e *Event) bool {
+	if !f.All && !e.IsSuccess() {
+		return false
+	}
+	if len(f.Sources) > 0 {
+		var ok bool
+		for _, a := range f.Sources {
+			if ok = a.Equal(e.Source); ok {
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(f.Tags) > 0 {
+		var ok bool
+		for _, t := range f.Tags {
+			if ok = t == e.Tag; ok {
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Events returns all events emitted by o in execution order that match
+// filter f.
+func (o Operation) Events(f EventFilter) []*Event {
+	var list []*Event
+	for i, op := range o.Contents {
+		for _, in := range op.Meta().InternalResults {
+			e, ok := in.Event()
+			if !ok {
+				continue
+			}
+			e.OpHash = o.Hash
+			e.Content = i
+			if f.Match(e) {
+				list = append(list, e)
+			}
+		}
+	}
+	return list
+}
+
+// Events returns all events emitted in block b that match filter f.
+func (b *Block) Events(f EventFilter) []*Event {
+	var list []*Event
+	for l, ops := range b.Operations {
+		for p, op := range ops {
+			for _, e := range op.Events(f) {
+				e.Block = b.Hash
+				e.Height = b.GetLevel()
+				e.List = l
+				e.Pos = p
+				list = append(list, e)
+			}
+		}
+	}
+	return list
+}
+
+// GetBlockEvents returns events emitted in block id that match filter f.
+func (c *Client) GetBlockEvents(ctx context.Context, id BlockID, f EventFilter) ([]*Event, error) {
+	b, err := c.GetBlock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return b.Events(f), nil
+}
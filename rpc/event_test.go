@@ -0,0 +1,79 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func treeEvent(src, tag, nonce, status string) string {
+	return `{"kind":"event","source":"` + src + `","nonce":` + nonce + `,"tag":"` + tag + `",
+		"type":{"prim":"pair","args":[{"prim":"nat","annots":["%amount"]},{"prim":"string","annots":["%memo"]}]},
+		"payload":{"prim":"Pair","args":[{"int":"` + nonce + `"},{"string":"hello"}]},
+		"result":{"status":"` + status + `","consumed_milligas":"100000"}}`
+}
+
+func TestEvents(t *testing.T) {
+	op := decodeTreeOp(t,
+		treeEvent(treeA, "paid", "0", "applied"),
+		treeInternal(treeA, treeB, 1, "1000"),
+		treeEvent(treeB, "other", "2", "applied"),
+		treeEvent(treeB, "paid", "3", "backtracked"),
+	)
+
+	all := op.Events(EventFilter{All: true})
+	require.Len(t, all, 3)
+	assert.Equal(t, treeA, all[0].Source.String())
+	assert.Equal(t, "paid", all[0].Tag)
+	assert.Equal(t, op.Hash, all[0].OpHash)
+	assert.True(t, all[0].IsSuccess())
+	assert.False(t, all[2].IsSuccess())
+
+	// failed operations are skipped by default
+	assert.Len(t, op.Events(EventFilter{}), 2)
+
+	list := op.Events(EventFilter{Tags: []string{"paid"}, All: true})
+	require.Len(t, list, 2)
+	assert.Equal(t, int64(0), list[0].Nonce)
+	assert.Equal(t, int64(3), list[1].Nonce)
+
+	list = op.Events(EventFilter{Sources: []tezos.Address{tezos.MustParseAddress(treeB)}})
+	require.Len(t, list, 1)
+	assert.Equal(t, "other", list[0].Tag)
+
+	// payload decoding
+	var payload struct {
+		Amount tezos.Z `json:"amount"`
+		Memo   string  `json:"memo"`
+	}
+	require.NoError(t, list[0].Unmarshal(&payload))
+	assert.Equal(t, int64(2), payload.Amount.Int64())
+	assert.Equal(t, "hello", payload.Memo)
+	m, err := list[0].Map()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", m.(map[string]interface{})["memo"])
+
+	// block events carry their position
+	b := &Block{
+		Hash:       tezos.MustParseBlockHash("BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2"),
+		Header:     BlockHeader{Level: 100},
+		Operations: [][]*Operation{{}, {}, {}, {op}},
+	}
+	list = b.Events(EventFilter{Tags: []string{"paid"}})
+	require.Len(t, list, 1)
+	assert.Equal(t, b.Hash, list[0].Block)
+	assert.Equal(t, int64(100), list[0].Height)
+	assert.Equal(t, 3, list[0].List)
+	assert.Equal(t, 0, list[0].Pos)
+
+	// call trees attach events to the emitting contract
+	root := op.CallTree()[0]
+	require.Len(t, root.Events, 1)
+	require.Len(t, root.Children, 1)
+	assert.Len(t, root.Children[0].Events, 2)
+}
@@ -0,0 +1,430 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/signer"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+const (
+	// GovernanceProposalQuorum is the minimum share of voting power in
+	// millionth a proposal must be upvoted by to win a proposal period.
+	GovernanceProposalQuorum = 50_000 // 5%
+
+	// GovernanceSupermajority is the minimum share of yay votes in millionth
+	// among yay and nay votes required to pass a ballot period.
+	GovernanceSupermajority = 800_000 // 80%
+
+	// GovernanceMaxProposals is the maximum number of proposals a delegate
+	// may upvote in a single proposal period.
+	GovernanceMaxProposals = 20
+)
+
+var (
+	ErrNotVoter         = errors.New("rpc: sender is not in the voter list")
+	ErrWrongVotePeriod  = errors.New("rpc: operation not allowed in current voting period")
+	ErrAlreadyVoted     = errors.New("rpc: sender has already voted in this period")
+	ErrNoVotingProposal = errors.New("rpc: no proposal under vote")
+)
+
+// GovernanceProjection describes possible outcomes of the current voting
+// period based on ballots cast so far.
+type GovernanceProjection struct {
+	Outcome     bool  `json:"outcome"`      // result if the period ended now
+	CanPass     bool  `json:"can_pass"`     // remaining power can still lead to success
+	CanFail     bool  `json:"can_fail"`     // remaining power can still lead to failure
+	RequiredYay int64 `json:"required_yay"` // extra yay power needed to pass, ballot periods only
+}
+
+// GovernanceStatus summarizes the state of on-chain governance at a block.
+// Participation, quorum and supermajority values are expressed in millionth.
+type GovernanceStatus struct {
+	Height    int64                  `json:"height"`
+	Period    int64                  `json:"period"`
+	Kind      tezos.VotingPeriodKind `json:"kind"`
+	Position  int64                  `json:"position"`
+	Remaining int64                  `json:"remaining"` // blocks left in period
+
+	// voters
+	Voters     int   `json:"voters"`
+	TotalPower int64 `json:"total_power"`
+
+	// proposal period
+	Proposals ProposalList       `json:"proposals,omitempty"` // sorted by upvotes
+	Leader    tezos.ProtocolHash `json:"leader"`              // invalid on tie or without upvotes
+
+	// ballot periods
+	Proposal      tezos.ProtocolHash `json:"proposal"`
+	Ballots       int                `json:"ballots"`
+	Yay           int64              `json:"yay"`
+	Nay           int64              `json:"nay"`
+	Pass          int64              `json:"pass"`
+	VotedPower    int64              `json:"voted_power"`
+	Quorum        int64              `json:"quorum"`
+	Supermajority int64              `json:"supermajority"` // yay share among yay and nay
+
+	Participation        int64                `json:"participation"`
+	QuorumReached        bool                 `json:"quorum_reached"`
+	SupermajorityReached bool                 `json:"supermajority_reached"`
+	Projection           GovernanceProjection `json:"projection"`
+}
+
+// IsBallotPeriod returns true when delegates vote on a single proposal.
+func (s *GovernanceStatus) IsBallotPeriod() bool {
+	return isBallotPeriod(s.Kind)
+}
+
+func isBallotPeriod(k tezos.VotingPeriodKind) bool {
+	return k == tezos.VotingPeriodExploration || k == tezos.VotingPeriodPromotion
+}
+
+// Governance reports voting progress and builds and sends proposals and
+// ballot operations for a baker.
+type Governance struct {
+	c *Client
+}
+
+func NewGovernance(c *Client) *Governance {
+	return &Governance{c: c}
+}
+
+// Status returns the current voting period, participation and projected
+// outcome at block id.
+func (g *Governance) Status(ctx context.Context, id BlockID) (*GovernanceStatus, error) {
+	meta, err := g.c.GetBlockMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	b := Block{Metadata: *meta}
+	info := b.GetVotingInfo()
+	s := &GovernanceStatus{
+		Height:    b.GetLevel(),
+		Period:    info.VotingPeriod.Index,
+		Kind:      info.VotingPeriod.Kind,
+		Position:  info.Position,
+		Remaining: info.Remaining,
+	}
+	voters, err := g.c.ListVoters(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		proposals ProposalList
+		ballots   BallotList
+		summary   BallotSummary
+		quorum    int
+	)
+	switch {
+	case s.Kind == tezos.VotingPeriodProposal:
+		if proposals, err = g.c.ListProposals(ctx, id); err != nil {
+			return nil, err
+		}
+	case isBallotPeriod(s.Kind):
+		if s.Proposal, err = g.c.GetVoteProposal(ctx, id); err != nil {
+			return nil, err
+		}
+		if ballots, err = g.c.ListBallots(ctx, id); err != nil {
+			return nil, err
+		}
+		if summary, err = g.c.GetVoteResult(ctx, id); err != nil {
+			return nil, err
+		}
+		if quorum, err = g.c.GetVoteQuorum(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	s.tally(voters, proposals, ballots, summary, quorum)
+	return s, nil
+}
+
+// tally computes participation and the projected outcome from voter
+// listings and votes. Quorum is given in percent * 100 as returned by
+// the node.
+func (s *GovernanceStatus) tally(voters VoterList, proposals ProposalList, ballots BallotList, summary BallotSummary, quorum int) {
+	s.Voters = len(voters)
+	s.TotalPower = 0
+	for _, v := range voters {
+		s.TotalPower += v.Power
+	}
+	if s.Kind == tezos.VotingPeriodProposal {
+		s.tallyProposals(proposals)
+		return
+	}
+	if !isBallotPeriod(s.Kind) {
+		return
+	}
+
+	s.Ballots = len(ballots)
+	s.Yay, s.Nay, s.Pass = int64(summary.Yay), int64(summary.Nay), int64(summary.Pass)
+	s.VotedPower = s.Yay + s.Nay + s.Pass
+	s.Quorum = int64(quorum) * 100
+	s.Participation = mulDiv(s.VotedPower, 1_000_000, s.TotalPower)
+	if s.Yay+s.Nay > 0 {
+		s.Supermajority = mulDiv(s.Yay, 1_000_000, s.Yay+s.Nay)
+	}
+	s.QuorumReached = s.TotalPower > 0 && reachesShare(s.VotedPower, s.TotalPower, s.Quorum)
+	s.SupermajorityReached = s.Yay > 0 && reachesShare(s.Yay, s.Yay+s.Nay, GovernanceSupermajority)
+
+	// project outcomes from the power that has not voted yet
+	remaining := s.TotalPower - s.VotedPower
+	if remaining < 0 {
+		remaining = 0
+	}
+	p := &s.Projection
+	p.Outcome = s.QuorumReached && s.SupermajorityReached
+	p.RequiredYay = requiredYay(s.Yay, s.Nay, s.VotedPower, s.TotalPower, s.Quorum)
+	p.CanPass = p.RequiredYay <= remaining
+	p.CanFail = !s.QuorumReached || !reachesShare(s.Yay, s.Yay+s.Nay+remaining, GovernanceSupermajority)
+}
+
+func (s *GovernanceStatus) tallyProposals(proposals ProposalList) {
+	s.Proposals = make(ProposalList, len(proposals))
+	copy(s.Proposals, proposals)
+	sort.SliceStable(s.Proposals, func(i, j int) bool {
+		return s.Proposals[i].Upvotes > s.Proposals[j].Upvotes
+	})
+	if len(s.Proposals) == 0 {
+		s.Projection.CanPass = s.TotalPower > 0
+		s.Projection.CanFail = true
+		return
+	}
+	best := s.Proposals[0]
+	tied := len(s.Proposals) > 1 && s.Proposals[1].Upvotes == best.Upvotes
+	if best.Upvotes > 0 && !tied {
+		s.Leader = best.Proposal
+	}
+	s.Participation = mulDiv(best.Upvotes, 1_000_000, s.TotalPower)
+	s.QuorumReached = s.TotalPower > 0 && reachesShare(best.Upvotes, s.TotalPower, GovernanceProposalQuorum)
+
+	// delegates may upvote multiple proposals, so any proposal may still
+	// gain or lose the lead until the period ends
+	p := &s.Projection
+	p.Outcome = s.QuorumReached && !tied
+	p.CanPass = s.TotalPower > 0
+	p.CanFail = true
+}
+
+// reachesShare returns true when part/total >= share millionth.
+func reachesShare(part, total, share int64) bool {
+	if total <= 0 {
+		return false
+	}
+	x := new(big.Int).Mul(big.NewInt(part), big.NewInt(1_000_000))
+	y := new(big.Int).Mul(big.NewInt(total), big.NewInt(share))
+	return x.Cmp(y) >= 0
+}
+
+// requiredYay returns the additional yay power needed to reach both quorum
+// and supermajority.
+func requiredYay(yay, nay, voted, total, quorum int64) int64 {
+	var need int64
+
+	// supermajority: (yay+x) * 1e6 >= sm * (yay+x+nay)
+	smNum := new(big.Int).Mul(big.NewInt(yay+nay), big.NewInt(GovernanceSupermajority))
+	smNum.Sub(smNum, new(big.Int).Mul(big.NewInt(yay), big.NewInt(1_000_000)))
+	if smNum.Sign() > 0 {
+		need = ceilDiv(smNum, big.NewInt(1_000_000-GovernanceSupermajority))
+	}
+
+	// quorum: (voted+x) * 1e6 >= quorum * total
+	qNum := new(big.Int).Mul(big.NewInt(total), big.NewInt(quorum))
+	if n := ceilDiv(qNum, big.NewInt(1_000_000)) - voted; n > need {
+		need = n
+	}
+
+	// at least one yay vote is needed
+	if yay+need == 0 {
+		need = 1
+	}
+	return need
+}
+
+func ceilDiv(x, y *big.Int) int64 {
+	q, m := new(big.Int).QuoRem(x, y, new(big.Int))
+	if m.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q.Int64()
+}
+
+// BuildProposals builds a proposals operation upvoting protocols for the
+// sender. It fails when the sender is not a voter, the chain is not in a
+// proposal period, a protocol was already upvoted by the sender or the
+// sender would exceed GovernanceMaxProposals upvotes in this period.
+func (g *Governance) BuildProposals(ctx context.Context, sender tezos.Address, proposals ...tezos.ProtocolHash) (*codec.Op, error) {
+	if len(proposals) == 0 || len(proposals) > GovernanceMaxProposals {
+		return nil, fmt.Errorf("rpc: invalid number of proposals %d", len(proposals))
+	}
+	period, err := g.check(ctx, sender, tezos.VotingPeriodProposal)
+	if err != nil {
+		return nil, err
+	}
+	d, err := g.c.GetDelegate(ctx, sender, Head)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUpvotes(d.VotingInfo.CurrentProposals, proposals); err != nil {
+		return nil, err
+	}
+	op := codec.NewOp().WithParams(g.c.Params).WithContents(&codec.Proposals{
+		Source:    sender,
+		Period:    int32(period),
+		Proposals: proposals,
+	})
+	return op, nil
+}
+
+// checkUpvotes fails when proposals repeat an upvote in current or in
+// proposals itself, or when all upvotes exceed GovernanceMaxProposals.
+func checkUpvotes(current, proposals []tezos.ProtocolHash) error {
+	seen := make(map[tezos.ProtocolHash]bool, len(current)+len(proposals))
+	for _, v := range current {
+		seen[v] = true
+	}
+	for _, v := range proposals {
+		if seen[v] {
+			return fmt.Errorf("%w: %s", ErrAlreadyVoted, v)
+		}
+		seen[v] = true
+	}
+	if n := len(current) + len(proposals); n > GovernanceMaxProposals {
+		return fmt.Errorf("rpc: %d upvotes exceed %d per period", n, GovernanceMaxProposals)
+	}
+	return nil
+}
+
+// BuildBallot builds a ballot operation for the proposal under vote. It
+// fails when the sender is not a voter, has already voted or the chain is
+// not in an exploration or promotion period.
+func (g *Governance) BuildBallot(ctx context.Context, sender tezos.Address, vote tezos.BallotVote) (*codec.Op, error) {
+	if !vote.IsValid() {
+		return nil, fmt.Errorf("rpc: invalid ballot %q", vote)
+	}
+	period, err := g.check(ctx, sender, tezos.VotingPeriodExploration, tezos.VotingPeriodPromotion)
+	if err != nil {
+		return nil, err
+	}
+	ballots, err := g.c.ListBallots(ctx, Head)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range ballots {
+		if v.Delegate.Equal(sender) {
+			return nil, ErrAlreadyVoted
+		}
+	}
+	proposal, err := g.c.GetVoteProposal(ctx, Head)
+	if err != nil {
+		return nil, err
+	}
+	if !proposal.IsValid() {
+		return nil, ErrNoVotingProposal
+	}
+	op := codec.NewOp().WithParams(g.c.Params).WithContents(&codec.Ballot{
+		Source:   sender,
+		Period:   int32(period),
+		Proposal: proposal,
+		Ballot:   vote,
+	})
+	return op, nil
+}
+
+// check ensures sender is a voter in one of the expected period kinds and
+// returns the current period index.
+func (g *Governance) check(ctx context.Context, sender tezos.Address, kinds ...tezos.VotingPeriodKind) (int64, error) {
+	meta, err := g.c.GetBlockMetadata(ctx, Head)
+	if err != nil {
+		return 0, err
+	}
+	b := Block{Metadata: *meta}
+	info := b.GetVotingInfo()
+	allowed := false
+	for _, k := range kinds {
+		allowed = allowed || info.VotingPeriod.Kind == k
+	}
+	if !allowed {
+		return 0, fmt.Errorf("%w: %s", ErrWrongVotePeriod, info.VotingPeriod.Kind)
+	}
+	voters, err := g.c.ListVoters(ctx, Head)
+	if err != nil {
+		return 0, err
+	}
+	for _, v := range voters {
+		if v.Delegate.Equal(sender) {
+			return info.VotingPeriod.Index, nil
+		}
+	}
+	return 0, ErrNotVoter
+}
+
+// Propose upvotes proposals for the sender in opts and broadcasts the
+// operation. Use the result to wait for inclusion.
+func (g *Governance) Propose(ctx context.Context, opts *CallOptions, proposals ...tezos.ProtocolHash) (*Result, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	s, addr, _, err := g.c.sender(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	op, err := g.BuildProposals(ctx, addr, proposals...)
+	if err != nil {
+		return nil, err
+	}
+	return g.send(ctx, op, s, addr, opts)
+}
+
+// Vote casts a ballot on the current proposal for the sender in opts and
+// broadcasts the operation. Use the result to wait for inclusion.
+func (g *Governance) Vote(ctx context.Context, vote tezos.BallotVote, opts *CallOptions) (*Result, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	s, addr, _, err := g.c.sender(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	op, err := g.BuildBallot(ctx, addr, vote)
+	if err != nil {
+		return nil, err
+	}
+	return g.send(ctx, op, s, addr, opts)
+}
+
+// send signs and broadcasts a voting operation. Voting operations have no
+// fees or counters and cannot be simulated, so they bypass SendAsync.
+func (g *Governance) send(ctx context.Context, op *codec.Op, s signer.Signer, addr tezos.Address, opts *CallOptions) (*Result, error) {
+	p := g.c.Params
+	if p == nil {
+		p = tezos.DefaultParams
+	}
+	op.WithTTL(opts.TTL)
+	ofs := p.MaxOperationsTTL - op.TTL
+	branch, err := g.c.GetBlockHash(ctx, NewBlockOffset(Head, -ofs))
+	if err != nil {
+		return nil, err
+	}
+	op.WithBranch(branch)
+	sig, err := s.SignOperation(ctx, addr, op)
+	if err != nil {
+		return nil, err
+	}
+	op.WithSignature(sig)
+
+	mon := g.c.observer(opts)
+	res, err := g.c.broadcast(ctx, op, opts)
+	if err != nil {
+		return nil, err
+	}
+	g.c.watch(res, mon, opts)
+	return res, nil
+}
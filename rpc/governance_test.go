@@ -0,0 +1,133 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/signer"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func governanceTestVoters() VoterList {
+	return VoterList{
+		{Delegate: tezos.MustParseAddress("tz1a95jRX4Z9WG7iHbPXZoQMLB9qN6wJvp4W"), Power: 600},
+		{Delegate: tezos.MustParseAddress("tz1hYc8FKJSztPJb8a9b4V4yQBGtk9t1FkEj"), Power: 300},
+		{Delegate: tezos.MustParseAddress("tz1dRKU4FQ9QRRQPdaH4zCR6gmCmXfcvcgtB"), Power: 100},
+	}
+}
+
+func TestGovernanceBallots(t *testing.T) {
+	type row struct {
+		Yay, Nay, Pass int64
+		Participation  int64
+		Supermajority  int64
+		Quorum         bool
+		Majority       bool
+		Projection     GovernanceProjection
+	}
+	for _, v := range []row{
+		// quorum missing
+		{400, 50, 0, 450_000, 888_888, false, true, GovernanceProjection{false, true, true, 50}},
+		// supermajority missing
+		{400, 150, 0, 550_000, 727_272, true, false, GovernanceProjection{false, true, true, 200}},
+		// decided even if everyone else votes nay
+		{900, 0, 0, 900_000, 1_000_000, true, true, GovernanceProjection{true, true, false, 0}},
+		// cannot pass anymore
+		{100, 500, 0, 600_000, 166_666, true, false, GovernanceProjection{false, false, true, 1900}},
+		// pass votes count towards quorum only
+		{0, 0, 600, 600_000, 0, true, false, GovernanceProjection{false, true, true, 1}},
+	} {
+		s := &GovernanceStatus{Kind: tezos.VotingPeriodExploration}
+		s.tally(governanceTestVoters(), nil, BallotList{{}, {}}, BallotSummary{
+			Yay:  Int64orString(v.Yay),
+			Nay:  Int64orString(v.Nay),
+			Pass: Int64orString(v.Pass),
+		}, 5000)
+		assert.Equal(t, 3, s.Voters)
+		assert.Equal(t, int64(1000), s.TotalPower)
+		assert.Equal(t, 2, s.Ballots)
+		assert.Equal(t, int64(500_000), s.Quorum)
+		assert.Equal(t, v, row{
+			s.Yay, s.Nay, s.Pass,
+			s.Participation,
+			s.Supermajority,
+			s.QuorumReached,
+			s.SupermajorityReached,
+			s.Projection,
+		})
+	}
+}
+
+func TestGovernanceProposals(t *testing.T) {
+	s := &GovernanceStatus{Kind: tezos.VotingPeriodProposal}
+	s.tally(governanceTestVoters(), ProposalList{
+		{tezos.ProtoV024, 40},
+		{tezos.ProtoV025, 120},
+	}, nil, BallotSummary{}, 0)
+	assert.Equal(t, tezos.ProtoV025, s.Proposals[0].Proposal)
+	assert.Equal(t, tezos.ProtoV025, s.Leader)
+	assert.Equal(t, int64(120_000), s.Participation)
+	assert.True(t, s.QuorumReached)
+	assert.True(t, s.Projection.Outcome)
+	assert.False(t, s.IsBallotPeriod())
+
+	// ties have no winner
+	s = &GovernanceStatus{Kind: tezos.VotingPeriodProposal}
+	s.tally(governanceTestVoters(), ProposalList{
+		{tezos.ProtoV024, 120},
+		{tezos.ProtoV025, 120},
+	}, nil, BallotSummary{}, 0)
+	assert.False(t, s.Leader.IsValid())
+	assert.True(t, s.QuorumReached)
+	assert.False(t, s.Projection.Outcome)
+
+	// below proposal quorum
+	s = &GovernanceStatus{Kind: tezos.VotingPeriodProposal}
+	s.tally(governanceTestVoters(), ProposalList{{tezos.ProtoV025, 49}}, nil, BallotSummary{}, 0)
+	assert.Equal(t, tezos.ProtoV025, s.Leader)
+	assert.False(t, s.QuorumReached)
+	assert.False(t, s.Projection.Outcome)
+	assert.True(t, s.Projection.CanPass)
+}
+
+func TestGovernanceUpvotes(t *testing.T) {
+	assert.NoError(t, checkUpvotes(nil, []tezos.ProtocolHash{tezos.ProtoV024, tezos.ProtoV025}))
+	assert.NoError(t, checkUpvotes([]tezos.ProtocolHash{tezos.ProtoV024}, []tezos.ProtocolHash{tezos.ProtoV025}))
+
+	// upvoted earlier in this period or twice in the same operation
+	err := checkUpvotes([]tezos.ProtocolHash{tezos.ProtoV025}, []tezos.ProtocolHash{tezos.ProtoV024, tezos.ProtoV025})
+	assert.ErrorIs(t, err, ErrAlreadyVoted)
+	err = checkUpvotes(nil, []tezos.ProtocolHash{tezos.ProtoV025, tezos.ProtoV025})
+	assert.ErrorIs(t, err, ErrAlreadyVoted)
+
+	// limit counts earlier upvotes
+	var current []tezos.ProtocolHash
+	for i := 1; i < GovernanceMaxProposals; i++ {
+		current = append(current, tezos.NewProtocolHash([]byte{byte(i)}))
+	}
+	assert.NoError(t, checkUpvotes(current, []tezos.ProtocolHash{tezos.ProtoV025}))
+	assert.Error(t, checkUpvotes(current, []tezos.ProtocolHash{tezos.ProtoV024, tezos.ProtoV025}))
+}
+
+func TestGovernanceSendDefaultParams(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+	c, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+	key, err := tezos.GenerateKey(tezos.KeyTypeEd25519)
+	require.NoError(t, err)
+
+	// clients without Init fall back to default params
+	g := NewGovernance(c)
+	op := codec.NewOp().WithContents(&codec.Proposals{Source: key.Address()})
+	_, err = g.send(context.Background(), op, signer.NewFromKey(key), key.Address(), NewCallOptions())
+	assert.Error(t, err)
+}
@@ -0,0 +1,459 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// HealthEventType is the kind of event reported by a HealthMonitor.
+type HealthEventType byte
+
+const (
+	HealthInvalid           HealthEventType = iota
+	HealthBaked                             // delegate baked or proposed the block
+	HealthMissedBake                        // block was baked at a later round by someone else
+	HealthLateRound                         // delegate baked the block after its first round
+	HealthAttested                          // attestation was included
+	HealthMissedAttestation                 // attestation was not included
+	HealthMissedDal                         // attestation was included without DAL content
+	HealthDeactivation                      // grace period ends soon or delegate is deactivated
+)
+
+func (t HealthEventType) String() string {
+	switch t {
+	case HealthBaked:
+		return "baked"
+	case HealthMissedBake:
+		return "missed_bake"
+	case HealthLateRound:
+		return "late_round"
+	case HealthAttested:
+		return "attested"
+	case HealthMissedAttestation:
+		return "missed_attestation"
+	case HealthMissedDal:
+		return "missed_dal"
+	case HealthDeactivation:
+		return "deactivation"
+	default:
+		return "invalid"
+	}
+}
+
+func (t HealthEventType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *HealthEventType) UnmarshalText(data []byte) error {
+	switch string(data) {
+	case "baked":
+		*t = HealthBaked
+	case "missed_bake":
+		*t = HealthMissedBake
+	case "late_round":
+		*t = HealthLateRound
+	case "attested":
+		*t = HealthAttested
+	case "missed_attestation":
+		*t = HealthMissedAttestation
+	case "missed_dal":
+		*t = HealthMissedDal
+	case "deactivation":
+		*t = HealthDeactivation
+	default:
+		return fmt.Errorf("invalid health event type %q", string(data))
+	}
+	return nil
+}
+
+// IsProblem returns true for events that need attention.
+func (t HealthEventType) IsProblem() bool {
+	switch t {
+	case HealthInvalid, HealthBaked, HealthAttested:
+		return false
+	default:
+		return true
+	}
+}
+
+// HealthEvent reports the outcome of a baking or attestation right or a
+// change in delegate status.
+type HealthEvent struct {
+	Type        HealthEventType `json:"type"`
+	Delegate    tezos.Address   `json:"delegate"`
+	Level       int64           `json:"level"` // level of the right
+	Cycle       int64           `json:"cycle"`
+	Block       tezos.BlockHash `json:"block"` // block that was checked
+	Time        time.Time       `json:"time"`
+	Round       int             `json:"round"`                  // round of the block at level
+	Expected    int             `json:"expected_round"`         // delegate's first baking round
+	Power       int             `json:"power,omitempty"`        // attestation power
+	Baker       tezos.Address   `json:"baker"`                  // block producer
+	Proposer    tezos.Address   `json:"proposer"`               // payload producer
+	GracePeriod int64           `json:"grace_period,omitempty"` // last active cycle
+	Deactivated bool            `json:"deactivated,omitempty"`
+}
+
+func (e *HealthEvent) String() string {
+	switch e.Type {
+	case HealthDeactivation:
+		return fmt.Sprintf("%s %s cycle=%d grace_period=%d deactivated=%t", e.Type, e.Delegate, e.Cycle, e.GracePeriod, e.Deactivated)
+	case HealthAttested, HealthMissedAttestation, HealthMissedDal:
+		return fmt.Sprintf("%s %s level=%d power=%d", e.Type, e.Delegate, e.Level, e.Power)
+	default:
+		return fmt.Sprintf("%s %s level=%d round=%d expected=%d baker=%s", e.Type, e.Delegate, e.Level, e.Round, e.Expected, e.Baker)
+	}
+}
+
+// HealthCallback receives health events in block order.
+type HealthCallback func(*HealthEvent)
+
+// healthRights are rights of monitored delegates in one cycle.
+type healthRights struct {
+	bake   map[int64]map[tezos.Address]int // level -> delegate -> first round
+	attest map[int64]map[tezos.Address]int // level -> delegate -> power
+}
+
+// HealthMonitor watches new blocks on an Observer and checks baking and
+// attestation rights of a set of delegates against the block's producer,
+// payload producer and included attestations. Blocks removed by a reorg
+// are not re-checked.
+type HealthMonitor struct {
+	c         *Client
+	obs       *Observer
+	delegates []tezos.Address
+	cbs       []HealthCallback
+
+	// MaxRound is the highest baking round loaded per level.
+	MaxRound int
+	// ExpectDal reports attestations without DAL content as missed.
+	ExpectDal bool
+	// GraceWarning is the number of cycles before the end of a delegate's
+	// grace period when deactivation warnings start.
+	GraceWarning int64
+
+	mu      sync.Mutex
+	rights  map[int64]*healthRights // by cycle
+	checked map[tezos.Address]int64 // last cycle the grace period was checked
+}
+
+func NewHealthMonitor(c *Client, delegates ...tezos.Address) *HealthMonitor {
+	return &HealthMonitor{
+		c:            c,
+		obs:          c.BlockObserver,
+		delegates:    delegates,
+		MaxRound:     4,
+		GraceWarning: 2,
+		rights:       make(map[int64]*healthRights),
+		checked:      make(map[tezos.Address]int64),
+	}
+}
+
+// WithObserver uses block observer o instead of the client's default.
+func (m *HealthMonitor) WithObserver(o *Observer) *HealthMonitor {
+	m.obs = o
+	return m
+}
+
+// OnEvent registers fn to receive all health events. Use
+// HealthEventType.IsProblem to filter alerts.
+func (m *HealthMonitor) OnEvent(fn HealthCallback) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cbs = append(m.cbs, fn)
+}
+
+func (m *HealthMonitor) emit(events []*HealthEvent) {
+	m.mu.Lock()
+	cbs := m.cbs
+	m.mu.Unlock()
+	for _, ev := range events {
+		for _, fn := range cbs {
+			fn(ev)
+		}
+	}
+}
+
+// Run checks every new block until ctx is canceled. Blocks are processed
+// outside the observer's callback so that slow RPC calls do not delay
+// other subscribers.
+func (m *HealthMonitor) Run(ctx context.Context) error {
+	heads := make(chan *BlockHeaderLogEntry, DefaultObserverDepth)
+	m.obs.Listen(m.c)
+	id := m.obs.Subscribe(tezos.ZeroOpHash, func(head *BlockHeaderLogEntry, _ int64, _, _ int, _ bool) bool {
+		select {
+		case heads <- head:
+		default:
+			m.c.Log.Warnf("health: dropped block %d, monitor is too slow", head.Level)
+		}
+		return false
+	})
+	defer m.obs.Unsubscribe(id)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case head := <-heads:
+			b, err := m.c.GetBlock(ctx, head.Hash)
+			if err != nil {
+				m.c.Log.Warnf("health: block %d: %v", head.Level, err)
+				continue
+			}
+			events, err := m.Check(ctx, b)
+			if err != nil {
+				m.c.Log.Warnf("health: block %d: %v", head.Level, err)
+			}
+			m.emit(events)
+		}
+	}
+}
+
+// Check returns health events for block b. Baking rights are checked at
+// the block's level and attestation rights at the predecessor level which
+// the block's attestations refer to.
+func (m *HealthMonitor) Check(ctx context.Context, b *Block) ([]*HealthEvent, error) {
+	info := b.GetLevelInfo()
+	bake, err := m.load(ctx, info.Cycle)
+	if err != nil {
+		return nil, err
+	}
+	attest := bake
+	if info.CyclePosition == 0 {
+		if attest, err = m.load(ctx, info.Cycle-1); err != nil {
+			return nil, err
+		}
+	}
+	events := checkHealth(b, bake, attest, m.ExpectDal)
+
+	deact, err := m.checkGracePeriod(ctx, b)
+	return append(events, deact...), err
+}
+
+// load returns rights of monitored delegates in cycle, fetching them once.
+func (m *HealthMonitor) load(ctx context.Context, cycle int64) (*healthRights, error) {
+	m.mu.Lock()
+	r, ok := m.rights[cycle]
+	m.mu.Unlock()
+	if ok {
+		return r, nil
+	}
+	r = &healthRights{
+		bake:   make(map[int64]map[tezos.Address]int),
+		attest: make(map[int64]map[tezos.Address]int),
+	}
+	bake, err := m.c.ListBakingRightsCycle(ctx, Head, cycle, m.MaxRound)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range bake {
+		if !m.isMonitored(v.Delegate) {
+			continue
+		}
+		round := v.Round + v.Priority
+		lvl, ok := r.bake[v.Level]
+		if !ok {
+			lvl = make(map[tezos.Address]int)
+			r.bake[v.Level] = lvl
+		}
+		if prev, ok := lvl[v.Delegate]; !ok || round < prev {
+			lvl[v.Delegate] = round
+		}
+	}
+	attest, err := m.c.ListEndorsingRightsCycle(ctx, Head, cycle)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range attest {
+		if !m.isMonitored(v.Delegate) {
+			continue
+		}
+		power := v.AttestationPower
+		if power == 0 {
+			power = v.Power()
+		}
+		lvl, ok := r.attest[v.Level]
+		if !ok {
+			lvl = make(map[tezos.Address]int)
+			r.attest[v.Level] = lvl
+		}
+		lvl[v.Delegate] += power
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rights[cycle] = r
+	for c := range m.rights {
+		if c < cycle-1 {
+			delete(m.rights, c)
+		}
+	}
+	return r, nil
+}
+
+func (m *HealthMonitor) isMonitored(addr tezos.Address) bool {
+	for _, v := range m.delegates {
+		if v.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGracePeriod reports delegates close to deactivation once per cycle.
+func (m *HealthMonitor) checkGracePeriod(ctx context.Context, b *Block) ([]*HealthEvent, error) {
+	cycle := b.GetCycle()
+	var events []*HealthEvent
+	for _, addr := range m.delegates {
+		m.mu.Lock()
+		last, ok := m.checked[addr]
+		m.mu.Unlock()
+		if ok && last >= cycle {
+			continue
+		}
+		d, err := m.c.GetDelegate(ctx, addr, b.Hash)
+		if err != nil {
+			return events, err
+		}
+		m.mu.Lock()
+		m.checked[addr] = cycle
+		m.mu.Unlock()
+		if !d.Deactivated && d.GracePeriod-cycle > m.GraceWarning {
+			continue
+		}
+		events = append(events, &HealthEvent{
+			Type:        HealthDeactivation,
+			Delegate:    addr,
+			Level:       b.GetLevel(),
+			Cycle:       cycle,
+			Block:       b.Hash,
+			Time:        b.GetTimestamp(),
+			GracePeriod: d.GracePeriod,
+			Deactivated: d.Deactivated,
+		})
+	}
+	return events, nil
+}
+
+// checkHealth matches baking rights at the level of b and attestation
+// rights at the predecessor level against the contents of b.
+func checkHealth(b *Block, bake, attest *healthRights, expectDal bool) []*HealthEvent {
+	var (
+		events   []*HealthEvent
+		level    = b.GetLevel()
+		round    = blockRound(b.Header)
+		baker    = b.Metadata.Baker
+		proposer = b.Metadata.Proposer
+	)
+	if !proposer.IsValid() {
+		proposer = baker
+	}
+	newEvent := func(typ HealthEventType, addr tezos.Address, lvl int64) *HealthEvent {
+		return &HealthEvent{
+			Type:     typ,
+			Delegate: addr,
+			Level:    lvl,
+			Cycle:    b.GetCycle(),
+			Block:    b.Hash,
+			Time:     b.GetTimestamp(),
+			Round:    round,
+			Baker:    baker,
+			Proposer: proposer,
+		}
+	}
+
+	// baking rights
+	for addr, first := range bake.bake[level] {
+		var ev *HealthEvent
+		switch {
+		case addr.Equal(baker) || addr.Equal(proposer):
+			ev = newEvent(HealthBaked, addr, level)
+			if addr.Equal(baker) && round > first {
+				ev.Type = HealthLateRound
+			}
+		case first < round:
+			ev = newEvent(HealthMissedBake, addr, level)
+		default:
+			continue
+		}
+		ev.Expected = first
+		events = append(events, ev)
+	}
+
+	// attestation rights
+	if rights := attest.attest[level-1]; len(rights) > 0 {
+		seen := attestedDelegates(b)
+		for addr, power := range rights {
+			typ := HealthMissedAttestation
+			if dal, ok := seen[addr]; ok {
+				typ = HealthAttested
+				if expectDal && !dal {
+					typ = HealthMissedDal
+				}
+			}
+			ev := newEvent(typ, addr, level-1)
+			ev.Power = power
+			events = append(events, ev)
+		}
+	}
+	sortHealthEvents(events)
+	return events
+}
+
+// attestedDelegates returns delegates with an attestation in b and whether
+// the attestation carried DAL content.
+func attestedDelegates(b *Block) map[tezos.Address]bool {
+	seen := make(map[tezos.Address]bool)
+	if len(b.Operations) == 0 {
+		return seen
+	}
+	for _, op := range b.Operations[0] {
+		for _, v := range op.Contents {
+			switch c := v.(type) {
+			case *Endorsement:
+				switch c.Kind() {
+				case tezos.OpTypeEndorsement, tezos.OpTypeEndorsementWithSlot,
+					tezos.OpTypeAttestation, tezos.OpTypeAttestationWithDal:
+					seen[c.Metadata.Delegate] = c.Kind() == tezos.OpTypeAttestationWithDal
+				}
+			case *AttestationsAggregate:
+				for i, m := range c.Metadata.CommitteeMetadata {
+					seen[m.Delegate] = i < len(c.Committee) && !c.Committee[i].DalAttestation.IsZero()
+				}
+			}
+		}
+	}
+	return seen
+}
+
+// blockRound returns the round of a Tenderbake block from its fitness.
+func blockRound(h BlockHeader) int {
+	if len(h.Fitness) < 5 || len(h.Fitness[4]) != 4 {
+		return h.Priority
+	}
+	return int(binary.BigEndian.Uint32(h.Fitness[4]))
+}
+
+// sortHealthEvents orders events by level, type and delegate so that
+// callbacks see a deterministic sequence.
+func sortHealthEvents(list []*HealthEvent) {
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Level != b.Level {
+			return a.Level < b.Level
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Delegate.String() < b.Delegate.String()
+	})
+}
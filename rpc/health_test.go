@@ -0,0 +1,112 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+const (
+	healthBaker = "tz1LggX2HUdvJ1tF4Fvv8fjsrzLeW4Jr9t2Q"
+	healthIdle  = "tz1N29q5T3jJ2i1JEWHax7q1NRkDMADj6fof"
+	healthOther = "tz1NNT9EERmcKekRq2vdv6e8TL3WQpY8AXSF"
+)
+
+func TestHealthCheck(t *testing.T) {
+	raw := `{"hash":"BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2",
+	"header":{"level":101,"timestamp":"2026-01-02T03:04:05Z","fitness":["02","00000065","","ffffffff","00000001"]},
+	"metadata":{"baker":"` + healthBaker + `","proposer":"` + healthBaker + `","level_info":{"level":101,"cycle":1,"cycle_position":5}},
+	"operations":[[
+		{"hash":"oo6JPEAy8VuMRGaFuMmLNFFGdJgiaKfnmT1CpHJfKP3Ye5ZahiP","contents":[
+			{"kind":"attestation_with_dal","slot":1,"level":100,"round":0,"dal_attestation":"1","metadata":{"delegate":"` + healthBaker + `"}}
+		]},
+		{"hash":"oo6JPEAy8VuMRGaFuMmLNFFGdJgiaKfnmT1CpHJfKP3Ye5ZahiP","contents":[
+			{"kind":"attestations_aggregate","consensus_content":{"level":100,"round":0},"committee":[{"slot":3,"dal_attestation":"0"}],
+			"metadata":{"committee":[{"delegate":"` + healthOther + `","consensus_pkh":"` + healthOther + `","consensus_power":7}]}}
+		]}
+	],[],[],[]]}`
+	b := &Block{}
+	decodeTestJSON(t, raw, b)
+	assert.Equal(t, 1, blockRound(b.Header))
+
+	var (
+		d1 = tezos.MustParseAddress(healthBaker)
+		d2 = tezos.MustParseAddress(healthIdle)
+		d3 = tezos.MustParseAddress(healthOther)
+	)
+	rights := &healthRights{
+		bake: map[int64]map[tezos.Address]int{
+			101: {d1: 1, d2: 0, d3: 3},
+		},
+		attest: map[int64]map[tezos.Address]int{
+			100: {d1: 10, d2: 5, d3: 7},
+		},
+	}
+
+	type row struct {
+		Type     HealthEventType
+		Addr     string
+		Level    int64
+		Expected int
+		Power    int
+	}
+	collect := func(list []*HealthEvent) []row {
+		var rows []row
+		for _, v := range list {
+			rows = append(rows, row{v.Type, v.Delegate.String(), v.Level, v.Expected, v.Power})
+			assert.Equal(t, int64(1), v.Cycle)
+			assert.Equal(t, 1, v.Round)
+		}
+		return rows
+	}
+	assert.Equal(t, []row{
+		{HealthAttested, healthBaker, 100, 0, 10},
+		{HealthMissedAttestation, healthIdle, 100, 0, 5},
+		{HealthMissedDal, healthOther, 100, 0, 7},
+		{HealthBaked, healthBaker, 101, 1, 0},
+		{HealthMissedBake, healthIdle, 101, 0, 0},
+	}, collect(checkHealth(b, rights, rights, true)))
+
+	// baked after the first own round
+	rights.bake[101][d1] = 0
+	assert.Equal(t, []row{
+		{HealthMissedBake, healthIdle, 101, 0, 0},
+		{HealthLateRound, healthBaker, 101, 0, 0},
+	}, collect(checkHealth(b, rights, rights, true))[3:])
+	rights.bake[101][d1] = 1
+
+	// DAL content is optional by default
+	events := checkHealth(b, rights, rights, false)
+	require.Len(t, events, 5)
+	assert.Equal(t, HealthAttested, events[1].Type)
+	assert.Equal(t, d3, events[1].Delegate)
+	assert.False(t, events[1].Type.IsProblem())
+	assert.True(t, events[2].Type.IsProblem())
+
+	// reproposed payloads count as baked
+	b.Metadata.Baker = d3
+	delete(rights.bake[101], d3)
+	events = checkHealth(b, rights, rights, false)
+	require.Len(t, events, 5)
+	assert.Equal(t, HealthBaked, events[3].Type)
+	assert.Equal(t, d1, events[3].Delegate)
+	assert.Equal(t, d3, events[3].Baker)
+	assert.Equal(t, d1, events[3].Proposer)
+}
+
+func TestHealthEventType(t *testing.T) {
+	for typ := HealthBaked; typ <= HealthDeactivation; typ++ {
+		buf, err := typ.MarshalText()
+		require.NoError(t, err)
+		var v HealthEventType
+		require.NoError(t, v.UnmarshalText(buf))
+		assert.Equal(t, typ, v)
+	}
+	var v HealthEventType
+	assert.Error(t, v.UnmarshalText([]byte("bad")))
+}
@@ -9,15 +9,16 @@ import (
 )
 
 type IssuanceParameters struct {
-	Cycle                   int64  `json:"cycle"`
-	BakingReward            int64  `json:"baking_reward_fixed_portion,string"`
-	BakingBonusPerSlot      *int64 `json:"baking_reward_bonus_per_slot,string,omitempty"`  // -v023
-	BakingBonusPerBlock     *int64 `json:"baking_reward_bonus_per_block,string,omitempty"` // v024
-	AttestingRewardPerSlot  *int64 `json:"attesting_reward_per_slot,string,omitempty"`     // -v023
-	AttestingRewardPerBlock *int64 `json:"attesting_reward_per_block,string,omitempty"`    // v024
-	LBSubsidy               int64  `json:"liquidity_baking_subsidy,string"`
-	SeedNonceTip            int64  `json:"seed_nonce_revelation_tip,string"`
-	VdfTip                  int64  `json:"vdf_revelation_tip,string"`
+	Cycle                      int64  `json:"cycle"`
+	BakingReward               int64  `json:"baking_reward_fixed_portion,string"`
+	BakingBonusPerSlot         *int64 `json:"baking_reward_bonus_per_slot,string,omitempty"`  // -v023
+	BakingBonusPerBlock        *int64 `json:"baking_reward_bonus_per_block,string,omitempty"` // v024
+	AttestingRewardPerSlot     *int64 `json:"attesting_reward_per_slot,string,omitempty"`     // -v023
+	AttestingRewardPerBlock    *int64 `json:"attesting_reward_per_block,string,omitempty"`    // v024
+	LBSubsidy                  int64  `json:"liquidity_baking_subsidy,string"`
+	SeedNonceTip               int64  `json:"seed_nonce_revelation_tip,string"`
+	VdfTip                     int64  `json:"vdf_revelation_tip,string"`
+	DalAttestingRewardPerShard *int64 `json:"dal_attesting_reward_per_shard,string,omitempty"` // v021+
 }
 
 // GetIssuance returns expected xtz issuance for known future cycles
@@ -0,0 +1,468 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// JournalStatus is the tracking state of a journaled operation.
+type JournalStatus byte
+
+const (
+	JournalPending  JournalStatus = iota // signed, possibly injected, not yet included
+	JournalIncluded                      // included in a canonical block
+	JournalExpired                       // not included before its branch expired
+	JournalFailed                        // injection failed or refused by the node
+)
+
+func (s JournalStatus) String() string {
+	switch s {
+	case JournalPending:
+		return "pending"
+	case JournalIncluded:
+		return "included"
+	case JournalExpired:
+		return "expired"
+	case JournalFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+func (s JournalStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *JournalStatus) UnmarshalText(data []byte) error {
+	switch string(data) {
+	case "pending":
+		*s = JournalPending
+	case "included":
+		*s = JournalIncluded
+	case "expired":
+		*s = JournalExpired
+	case "failed":
+		*s = JournalFailed
+	default:
+		return fmt.Errorf("invalid journal status %q", string(data))
+	}
+	return nil
+}
+
+// JournalEntry records a signed operation and its last known tracking state.
+type JournalEntry struct {
+	Hash     tezos.OpHash    `json:"hash"`                  // hash of the signed operation
+	Replaced []tezos.OpHash  `json:"replaced_by,omitempty"` // hashes of fee-bumped replacements
+	Source   tezos.Address   `json:"source"`
+	Branch   tezos.BlockHash `json:"branch"`
+	Data     tezos.HexBytes  `json:"data"` // signed operation bytes
+	Status   JournalStatus   `json:"status"`
+	Block    tezos.BlockHash `json:"block,omitempty"`
+	Height   int64           `json:"height,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Created  time.Time       `json:"created"`
+	Updated  time.Time       `json:"updated"`
+}
+
+// Hashes returns the original operation hash and all replacement hashes.
+func (e *JournalEntry) Hashes() []tezos.OpHash {
+	return append([]tezos.OpHash{e.Hash}, e.Replaced...)
+}
+
+func (e *JournalEntry) addReplaced(oh tezos.OpHash) {
+	for _, h := range e.Hashes() {
+		if h.Equal(oh) {
+			return
+		}
+	}
+	e.Replaced = append(e.Replaced, oh)
+}
+
+// JournalStore persists journal entries. Put creates or replaces the entry
+// with the same hash. Implementations must be safe for concurrent use.
+type JournalStore interface {
+	Put(e *JournalEntry) error
+	List() ([]*JournalEntry, error)
+	Delete(hash tezos.OpHash) error
+}
+
+// Journal records every signed operation before injection and tracks it
+// until it is included, expires or fails. After a restart Resume picks up
+// pending entries so that every sent operation is tracked at least once.
+type Journal struct {
+	store JournalStore
+	c     *Client
+	mu    sync.Mutex
+}
+
+func NewJournal(c *Client, store JournalStore) *Journal {
+	return &Journal{
+		store: store,
+		c:     c,
+	}
+}
+
+// Store returns the underlying journal store.
+func (j *Journal) Store() JournalStore {
+	return j.store
+}
+
+// Entries returns all journal entries in creation order.
+func (j *Journal) Entries() ([]*JournalEntry, error) {
+	list, err := j.store.List()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(list, func(i, k int) bool { return list[i].Created.Before(list[k].Created) })
+	return list, nil
+}
+
+// Prune deletes all entries that are no longer pending.
+func (j *Journal) Prune() error {
+	list, err := j.store.List()
+	if err != nil {
+		return err
+	}
+	for _, e := range list {
+		if e.Status != JournalPending {
+			if err := j.store.Delete(e.Hash); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// record journals signed operation op before injection.
+func (j *Journal) record(op *codec.Op) (*JournalEntry, error) {
+	now := time.Now().UTC()
+	e := &JournalEntry{
+		Hash:    op.Hash(),
+		Source:  op.Source,
+		Branch:  op.Branch,
+		Data:    op.Bytes(),
+		Status:  JournalPending,
+		Created: now,
+		Updated: now,
+	}
+	if err := j.store.Put(e); err != nil {
+		return nil, fmt.Errorf("journal: %w", err)
+	}
+	return e, nil
+}
+
+// replace links replacement hash oh to entry e before it is injected.
+func (j *Journal) replace(e *JournalEntry, oh tezos.OpHash) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	e.addReplaced(oh)
+	e.Updated = time.Now().UTC()
+	if err := j.store.Put(e); err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	return nil
+}
+
+// update applies fn to entry e and persists the result.
+func (j *Journal) update(e *JournalEntry, fn func(e *JournalEntry)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(e)
+	e.Updated = time.Now().UTC()
+	if err := j.store.Put(e); err != nil {
+		j.c.Log.Warnf("journal: update %s failed: %v", e.Hash, err)
+	}
+}
+
+// fail marks entry e as failed with err.
+func (j *Journal) fail(e *JournalEntry, err error) {
+	j.update(e, func(e *JournalEntry) {
+		e.Status = JournalFailed
+		e.Error = err.Error()
+	})
+}
+
+// broadcastFailed records a failed injection of entry e. Operations the
+// node refused are marked failed. Other errors leave the outcome unknown,
+// so e stays pending with the error for Resume to reconcile.
+func (j *Journal) broadcastFailed(e *JournalEntry, err error) {
+	var berr *BroadcastError
+	if errors.As(err, &berr) && !berr.IsRefused() {
+		j.update(e, func(e *JournalEntry) {
+			e.Error = err.Error()
+		})
+		return
+	}
+	j.fail(e, err)
+}
+
+// track keeps entry e in sync with the lifecycle of result res.
+func (j *Journal) track(e *JournalEntry, res *Result) {
+	res.OnEvent(func(ev ResultEvent) {
+		switch ev.State {
+		case ResultIncluded:
+			j.update(e, func(e *JournalEntry) {
+				e.Status = JournalIncluded
+				e.Block = ev.Block
+				e.Height = ev.Height
+			})
+		case ResultReorged:
+			j.update(e, func(e *JournalEntry) {
+				e.Status = JournalPending
+				e.Block = tezos.BlockHash{}
+				e.Height = 0
+			})
+		case ResultReplaced:
+			j.update(e, func(e *JournalEntry) {
+				e.addReplaced(ev.Hash)
+			})
+		}
+	})
+	go func() {
+		<-res.Done()
+		err := res.Err()
+		switch {
+		case err == nil:
+			// inclusion is recorded by the event handler
+		case errors.Is(err, ErrTTLExceeded):
+			j.update(e, func(e *JournalEntry) {
+				e.Status = JournalExpired
+			})
+		case errors.Is(err, ErrCanceled), errors.Is(err, context.Canceled):
+			// the operation may still be included, keep it pending
+		default:
+			j.fail(e, err)
+		}
+	}()
+}
+
+// Resume checks all pending entries against the canonical chain. Entries
+// found in a block are marked included, entries whose branch has expired
+// are marked expired. All other entries are tracked on the block observer
+// again and their results are returned. opts controls the number of
+// confirmations and the observer used.
+func (j *Journal) Resume(ctx context.Context, opts *CallOptions) ([]*Result, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	list, err := j.Entries()
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]*JournalEntry, 0, len(list))
+	for _, e := range list {
+		if e.Status == JournalPending {
+			pending = append(pending, e)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	mon := j.c.BlockObserver
+	if opts.Observer != nil {
+		mon = opts.Observer
+	}
+	mon.Listen(j.c)
+
+	head, err := j.c.GetBlockHeader(ctx, Head)
+	if err != nil {
+		return nil, err
+	}
+	params := j.c.Params
+	if params == nil {
+		params = tezos.DefaultParams
+	}
+
+	// find the branch level of each entry
+	expires := make(map[tezos.OpHash]int64)
+	start := head.Level
+	for _, e := range pending {
+		b, err := j.c.GetBlockHeader(ctx, e.Branch)
+		if err != nil {
+			return nil, fmt.Errorf("journal: branch of %s: %w", e.Hash, err)
+		}
+		expires[e.Hash] = b.Level + params.MaxOperationsTTL
+		if b.Level < start {
+			start = b.Level
+		}
+	}
+
+	// subscribe first so that no block is missed while scanning
+	results := make(map[tezos.OpHash]*Result)
+	for _, e := range pending {
+		ttl := expires[e.Hash] - head.Level
+		if ttl <= 0 {
+			continue
+		}
+		res := NewResult(e.Hash).WithTTL(ttl).WithConfirmations(opts.Confirmations)
+		res.emit(ResultEvent{State: ResultBroadcast})
+		res.Listen(mon)
+		for _, h := range e.Replaced {
+			res.replace(h)
+		}
+		j.track(e, res)
+		results[e.Hash] = res
+	}
+
+	// scan the canonical chain back to the oldest branch
+	found, err := j.scan(ctx, head, start, pending)
+	if err != nil {
+		for _, res := range results {
+			res.Cancel()
+		}
+		return nil, err
+	}
+
+	var active []*Result
+	for _, e := range pending {
+		res := results[e.Hash]
+		if m, ok := found[e.Hash]; ok {
+			if res != nil {
+				res.Cancel()
+			}
+			j.update(e, func(e *JournalEntry) {
+				e.Status = JournalIncluded
+				e.Block = m.block
+				e.Height = m.level
+			})
+			continue
+		}
+		if res == nil {
+			j.update(e, func(e *JournalEntry) {
+				e.Status = JournalExpired
+			})
+			continue
+		}
+		j.c.MempoolObserver.ListenMempool(j.c)
+		res.ListenMempool(j.c.MempoolObserver)
+		active = append(active, res)
+	}
+	return active, nil
+}
+
+// scan walks the chain from head back to level start and returns blocks that
+// contain any hash of pending entries.
+func (j *Journal) scan(ctx context.Context, head *BlockHeader, start int64, pending []*JournalEntry) (map[tezos.OpHash]observerMatch, error) {
+	want := make(map[tezos.OpHash]tezos.OpHash)
+	for _, e := range pending {
+		for _, h := range e.Hashes() {
+			want[h] = e.Hash
+		}
+	}
+	found := make(map[tezos.OpHash]observerMatch)
+	hash, level := head.Hash, head.Level
+	for level > start && len(found) < len(pending) {
+		ops, err := j.c.GetBlockOperationHashes(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		for l, list := range ops {
+			for n, h := range list {
+				if id, ok := want[h]; ok {
+					found[id] = observerMatch{hash, level, l, n}
+				}
+			}
+		}
+		b, err := j.c.GetBlockHeader(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		hash, level = b.Predecessor, b.Level-1
+	}
+	return found, nil
+}
+
+// FileJournal is a JournalStore that keeps one JSON file per entry in a
+// directory. Files are replaced atomically on update.
+type FileJournal struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewFileJournal(dir string) (*FileJournal, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileJournal{dir: dir}, nil
+}
+
+func (f *FileJournal) path(hash tezos.OpHash) string {
+	return filepath.Join(f.dir, hash.String()+".json")
+}
+
+func (f *FileJournal) Put(e *JournalEntry) error {
+	buf, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(e.Hash))
+}
+
+func (f *FileJournal) List() ([]*JournalEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	files, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*JournalEntry, 0, len(files))
+	for _, v := range files {
+		if v.IsDir() || strings.HasPrefix(v.Name(), ".") || filepath.Ext(v.Name()) != ".json" {
+			continue
+		}
+		buf, err := os.ReadFile(filepath.Join(f.dir, v.Name()))
+		if err != nil {
+			return nil, err
+		}
+		e := &JournalEntry{}
+		if err := json.Unmarshal(buf, e); err != nil {
+			return nil, fmt.Errorf("journal: %s: %w", v.Name(), err)
+		}
+		list = append(list, e)
+	}
+	return list, nil
+}
+
+func (f *FileJournal) Delete(hash tezos.OpHash) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := os.Remove(f.path(hash))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
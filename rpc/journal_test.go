@@ -0,0 +1,131 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func TestJournalResume(t *testing.T) {
+	chain, cli := newTestChain(t)
+	params := *tezos.DefaultParams
+	params.MaxOperationsTTL = 3
+	params.MinimalBlockDelay = 5 * time.Millisecond
+	cli.Params = &params
+
+	var (
+		included = tezos.NewOpHash(append(make([]byte, 31), 0x41))
+		replaced = tezos.NewOpHash(append(make([]byte, 31), 0x42))
+		expired  = tezos.NewOpHash(append(make([]byte, 31), 0x43))
+		pending  = tezos.NewOpHash(append(make([]byte, 31), 0x44))
+		failed   = tezos.NewOpHash(append(make([]byte, 31), 0x45))
+	)
+
+	// chain a1..a5, the replacement of included lands in a3
+	a1 := chain.add(nil, 1)
+	a2 := chain.add(a1, 2)
+	a3 := chain.add(a2, 3, replaced)
+	a4 := chain.add(a3, 4)
+	a5 := chain.add(a4, 5)
+
+	store, err := NewFileJournal(t.TempDir())
+	require.NoError(t, err)
+	now := time.Now().UTC()
+	for i, e := range []*JournalEntry{
+		{Hash: included, Replaced: []tezos.OpHash{replaced}, Branch: a1.hash},
+		{Hash: expired, Branch: a1.hash},
+		{Hash: pending, Branch: a4.hash},
+		{Hash: failed, Branch: a1.hash, Status: JournalFailed},
+	} {
+		e.Created = now.Add(time.Duration(i) * time.Second)
+		require.NoError(t, store.Put(e))
+	}
+
+	// restart with a fresh client
+	cli.WithJournal(store)
+	obs := NewObserver().WithDelay(5 * time.Millisecond)
+	defer obs.Close()
+	opts := NewCallOptions()
+	opts.Observer = obs
+	opts.Confirmations = 1
+	results, err := cli.Journal.Resume(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, pending, results[0].Hash())
+
+	status := func() map[tezos.OpHash]*JournalEntry {
+		list, err := cli.Journal.Entries()
+		require.NoError(t, err)
+		m := make(map[tezos.OpHash]*JournalEntry)
+		for _, e := range list {
+			m[e.Hash] = e
+		}
+		return m
+	}
+	m := status()
+	assert.Equal(t, JournalIncluded, m[included].Status)
+	assert.Equal(t, a3.hash, m[included].Block)
+	assert.Equal(t, a3.level, m[included].Height)
+	assert.Equal(t, JournalExpired, m[expired].Status)
+	assert.Equal(t, JournalPending, m[pending].Status)
+	assert.Equal(t, JournalFailed, m[failed].Status)
+
+	// the resumed operation is included in a new block
+	require.Eventually(t, func() bool { return obs.isKnownLocked(a5) }, time.Second, 5*time.Millisecond)
+	a6 := chain.add(a5, 6, pending)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.True(t, results[0].WaitContext(ctx))
+	require.NoError(t, results[0].Err())
+	require.Eventually(t, func() bool { return status()[pending].Status == JournalIncluded }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, a6.hash, status()[pending].Block)
+
+	// prune removes finished entries
+	require.NoError(t, cli.Journal.Prune())
+	list, err := cli.Journal.Entries()
+	require.NoError(t, err)
+	assert.Len(t, list, 0)
+}
+
+func TestJournalBroadcastFailed(t *testing.T) {
+	store, err := NewFileJournal(t.TempDir())
+	require.NoError(t, err)
+	cli, err := NewClient("http://localhost", nil)
+	require.NoError(t, err)
+	cli.WithJournal(store)
+
+	var (
+		unknown = &JournalEntry{Hash: tezos.NewOpHash(append(make([]byte, 31), 0x41))}
+		refused = &JournalEntry{Hash: tezos.NewOpHash(append(make([]byte, 31), 0x42))}
+	)
+	for _, e := range []*JournalEntry{unknown, refused} {
+		require.NoError(t, store.Put(e))
+	}
+
+	// the operation may have reached the network
+	cli.Journal.broadcastFailed(unknown, &BroadcastError{Hash: unknown.Hash, Err: errors.New("connection reset")})
+	// the node rejected the operation
+	rerr := &rpcError{httpError: &httpError{statusCode: 500}, errors: Errors{&GenericError{ID: "failure", Kind: "temporary"}}}
+	cli.Journal.broadcastFailed(refused, &BroadcastError{Hash: refused.Hash, Err: rerr})
+
+	list, err := cli.Journal.Entries()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	for _, e := range list {
+		switch e.Hash {
+		case unknown.Hash:
+			assert.Equal(t, JournalPending, e.Status)
+			assert.Equal(t, "connection reset", e.Error)
+		case refused.Hash:
+			assert.Equal(t, JournalFailed, e.Status)
+		}
+	}
+}
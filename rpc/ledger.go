@@ -0,0 +1,529 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// LedgerType is the normalised accounting class of a balance change.
+type LedgerType byte
+
+const (
+	LedgerInvalid    LedgerType = iota // unknown or unsupported balance update
+	LedgerTransfer                     // tez moved between accounts
+	LedgerFee                          // operation fee paid or collected by the baker
+	LedgerBurn                         // storage and allocation burn
+	LedgerReward                       // baking, attesting, nonce and evidence rewards
+	LedgerMint                         // subsidy, invoice and other minted tez
+	LedgerStake                        // spendable balance moved to stake
+	LedgerUnstake                      // stake moved to unstaked deposits
+	LedgerFinalize                     // unstaked deposits returned to spendable balance
+	LedgerSlash                        // stake or deposits lost to punishments
+	LedgerDeposit                      // legacy frozen deposits, fees and rewards
+	LedgerBond                         // smart rollup bonds
+	LedgerActivation                   // commitment activation
+)
+
+func (t LedgerType) String() string {
+	switch t {
+	case LedgerTransfer:
+		return "transfer"
+	case LedgerFee:
+		return "fee"
+	case LedgerBurn:
+		return "burn"
+	case LedgerReward:
+		return "reward"
+	case LedgerMint:
+		return "mint"
+	case LedgerStake:
+		return "stake"
+	case LedgerUnstake:
+		return "unstake"
+	case LedgerFinalize:
+		return "finalize"
+	case LedgerSlash:
+		return "slash"
+	case LedgerDeposit:
+		return "deposit"
+	case LedgerBond:
+		return "bond"
+	case LedgerActivation:
+		return "activation"
+	default:
+		return "invalid"
+	}
+}
+
+func (t LedgerType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *LedgerType) UnmarshalText(data []byte) error {
+	for v := LedgerTransfer; v <= LedgerActivation; v++ {
+		if v.String() == string(data) {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid ledger type %q", string(data))
+}
+
+// LedgerAccount identifies which balance of an address changed.
+type LedgerAccount byte
+
+const (
+	AccountInvalid    LedgerAccount = iota
+	AccountSpendable                // spendable balance
+	AccountStaked                   // staked or frozen deposits
+	AccountUnstaked                 // unstaked deposits awaiting finalization
+	AccountLegacy                   // pre-Ithaca frozen deposits, fees and rewards
+	AccountBonds                    // frozen smart rollup bonds
+	AccountCommitment               // fundraiser commitment
+)
+
+func (a LedgerAccount) String() string {
+	switch a {
+	case AccountSpendable:
+		return "spendable"
+	case AccountStaked:
+		return "staked"
+	case AccountUnstaked:
+		return "unstaked"
+	case AccountLegacy:
+		return "legacy"
+	case AccountBonds:
+		return "bonds"
+	case AccountCommitment:
+		return "commitment"
+	default:
+		return "invalid"
+	}
+}
+
+func (a LedgerAccount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *LedgerAccount) UnmarshalText(data []byte) error {
+	for v := AccountSpendable; v <= AccountCommitment; v++ {
+		if v.String() == string(data) {
+			*a = v
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid ledger account %q", string(data))
+}
+
+// LedgerEntry is one side of a double-entry record: the change of a single
+// account owned by Address. The opposite side is described by Counterparty
+// and Counteraccount. Protocol accounts like minted, burned or the block fee
+// accumulator have no owner and are only referenced as counteraccount.
+type LedgerEntry struct {
+	Block          tezos.BlockHash `json:"block"`
+	Height         int64           `json:"height"`
+	Cycle          int64           `json:"cycle"`
+	Time           time.Time       `json:"time"`
+	OpHash         tezos.OpHash    `json:"op_hash,omitempty"` // empty for block updates
+	Type           LedgerType      `json:"type"`
+	Address        tezos.Address   `json:"address"`
+	Account        LedgerAccount   `json:"account"`
+	Amount         int64           `json:"amount"` // signed change in mutez, positive is a credit
+	Counterparty   tezos.Address   `json:"counterparty,omitempty"`
+	Counteraccount string          `json:"counteraccount"`     // account name or protocol category
+	Kind           string          `json:"kind"`               // raw balance update kind
+	Category       string          `json:"category,omitempty"` // raw balance update category
+	Origin         string          `json:"origin,omitempty"`   // raw balance update origin
+}
+
+// Ledger classifies balance updates from blocks and operation receipts into
+// normalised accounting entries. Set Addresses to record entries for the
+// listed accounts only and Params to derive cycles for receipts.
+type Ledger struct {
+	Addresses []tezos.Address
+	Params    *tezos.Params
+	Entries   []*LedgerEntry
+}
+
+func NewLedger(addrs ...tezos.Address) *Ledger {
+	return &Ledger{Addresses: addrs}
+}
+
+// ledgerPos carries the block position of balance updates.
+type ledgerPos struct {
+	block  tezos.BlockHash
+	height int64
+	cycle  int64
+	time   time.Time
+	op     tezos.OpHash
+}
+
+// AddBlock records all balance updates in block b: block rewards, implicit
+// operations, operation fees and results including internal results.
+func (l *Ledger) AddBlock(b *Block) {
+	pos := ledgerPos{
+		block:  b.Hash,
+		height: b.GetLevel(),
+		cycle:  b.GetCycle(),
+		time:   b.GetTimestamp(),
+	}
+	l.add(pos, b.Metadata.BalanceUpdates)
+	for _, r := range b.Metadata.ImplicitOperationsResults {
+		l.add(pos, r.BalanceUpdates)
+	}
+	for _, ops := range b.Operations {
+		for _, op := range ops {
+			l.addOperation(pos, op)
+		}
+	}
+}
+
+// AddReceipt records all balance updates of an operation receipt.
+func (l *Ledger) AddReceipt(r *Receipt) {
+	if r.Op == nil {
+		return
+	}
+	pos := ledgerPos{
+		block:  r.Block,
+		height: r.Height,
+	}
+	if l.Params != nil && r.Height > 0 {
+		pos.cycle = l.Params.CycleFromHeight(r.Height)
+	}
+	l.addOperation(pos, r.Op)
+}
+
+func (l *Ledger) addOperation(pos ledgerPos, op *Operation) {
+	pos.op = op.Hash
+	for _, v := range op.Contents {
+		meta := v.Meta()
+		l.add(pos, meta.BalanceUpdates)
+		l.add(pos, meta.Result.BalanceUpdates)
+		for _, in := range meta.InternalResults {
+			l.add(pos, in.Result.BalanceUpdates)
+		}
+	}
+}
+
+// add records one entry per owned account in each zero-sum group of list.
+func (l *Ledger) add(pos ledgerPos, list BalanceUpdates) {
+	for _, group := range list.groups() {
+		l.addGroup(pos, group)
+	}
+}
+
+// groups splits balance updates into consecutive groups that sum to zero,
+// i.e. the debit and credit sides of a single movement. A trailing group
+// that does not balance is returned as is.
+func (l BalanceUpdates) groups() []BalanceUpdates {
+	var (
+		list  []BalanceUpdates
+		start int
+		sum   int64
+	)
+	for i, u := range l {
+		sum += u.Change
+		if sum == 0 {
+			list = append(list, l[start:i+1])
+			start = i + 1
+		}
+	}
+	if start < len(l) {
+		list = append(list, l[start:])
+	}
+	return list
+}
+
+func (l *Ledger) addGroup(pos ledgerPos, group BalanceUpdates) {
+	for i, u := range group {
+		acc := ledgerAccount(u)
+		if acc == AccountInvalid {
+			continue
+		}
+		addr := u.Address()
+		if !l.wants(addr) {
+			continue
+		}
+		cp := counterpart(group, i)
+		e := &LedgerEntry{
+			Block:    pos.block,
+			Height:   pos.height,
+			Cycle:    pos.cycle,
+			Time:     pos.time,
+			OpHash:   pos.op,
+			Type:     classify(u, cp),
+			Address:  addr,
+			Account:  acc,
+			Amount:   u.Change,
+			Kind:     u.Kind,
+			Category: u.Category,
+			Origin:   u.Origin,
+		}
+		if cp != nil {
+			if a := ledgerAccount(*cp); a != AccountInvalid {
+				e.Counterparty = cp.Address()
+				e.Counteraccount = a.String()
+			} else {
+				e.Counteraccount = strings.TrimSpace(cp.Kind + " " + cp.Category)
+			}
+		}
+		l.Entries = append(l.Entries, e)
+	}
+}
+
+func (l *Ledger) wants(addr tezos.Address) bool {
+	if len(l.Addresses) == 0 {
+		return true
+	}
+	for _, a := range l.Addresses {
+		if a.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// counterpart returns the first update of opposite sign in group, or the
+// other update of a two-element group when amounts are zero.
+func counterpart(group BalanceUpdates, i int) *BalanceUpdate {
+	u := group[i]
+	for k := range group {
+		if k == i {
+			continue
+		}
+		if (u.Change < 0 && group[k].Change > 0) || (u.Change > 0 && group[k].Change < 0) {
+			return &group[k]
+		}
+	}
+	if len(group) == 2 {
+		return &group[1-i]
+	}
+	return nil
+}
+
+// ledgerAccount maps an update to an owned account or AccountInvalid for
+// protocol accounts and staking pseudotokens.
+func ledgerAccount(u BalanceUpdate) LedgerAccount {
+	switch u.Kind {
+	case CONTRACT:
+		return AccountSpendable
+	case "freezer":
+		switch u.Category {
+		case "deposits":
+			return AccountStaked
+		case "unstaked_deposits":
+			return AccountUnstaked
+		default:
+			return AccountLegacy
+		}
+	case "frozen_bonds":
+		return AccountBonds
+	case "commitment":
+		return AccountCommitment
+	default:
+		// minted, burned, accumulator, staking
+		return AccountInvalid
+	}
+}
+
+// classify returns the accounting type of update u with counterpart cp.
+func classify(u BalanceUpdate, cp *BalanceUpdate) LedgerType {
+	if cp == nil {
+		return LedgerInvalid
+	}
+	switch cp.Kind {
+	case "minted":
+		switch {
+		case strings.Contains(cp.Category, "rewards"), strings.Contains(cp.Category, "bonuses"):
+			return LedgerReward
+		case cp.Category == "commitment":
+			return LedgerActivation
+		default:
+			return LedgerMint
+		}
+	case "burned":
+		if strings.Contains(cp.Category, "punishments") {
+			return LedgerSlash
+		}
+		return LedgerBurn
+	case "accumulator":
+		return LedgerFee
+	case "commitment":
+		return LedgerActivation
+	case "frozen_bonds":
+		return LedgerBond
+	}
+	from, to := ledgerAccount(u), ledgerAccount(*cp)
+	if u.Change > 0 {
+		from, to = to, from
+	}
+	switch {
+	case from == AccountSpendable && to == AccountSpendable:
+		return LedgerTransfer
+	case from == AccountSpendable && to == AccountStaked:
+		return LedgerStake
+	case from == AccountStaked && to == AccountUnstaked:
+		return LedgerUnstake
+	case from == AccountUnstaked && to == AccountSpendable:
+		return LedgerFinalize
+	case from == AccountLegacy || to == AccountLegacy:
+		return LedgerDeposit
+	case from == AccountBonds || to == AccountBonds:
+		return LedgerBond
+	default:
+		return LedgerTransfer
+	}
+}
+
+// LedgerSummary aggregates entries of one address in one cycle.
+type LedgerSummary struct {
+	Address tezos.Address        `json:"address"`
+	Cycle   int64                `json:"cycle"`
+	Credit  int64                `json:"credit"`
+	Debit   int64                `json:"debit"`
+	Net     int64                `json:"net"`
+	ByType  map[LedgerType]int64 `json:"by_type"` // net change per type
+}
+
+// Summary aggregates entries per address and cycle, ordered by cycle
+// and address.
+func (l *Ledger) Summary() []*LedgerSummary {
+	type key struct {
+		addr  string
+		cycle int64
+	}
+	m := make(map[key]*LedgerSummary)
+	for _, e := range l.Entries {
+		k := key{e.Address.String(), e.Cycle}
+		s, ok := m[k]
+		if !ok {
+			s = &LedgerSummary{
+				Address: e.Address,
+				Cycle:   e.Cycle,
+				ByType:  make(map[LedgerType]int64),
+			}
+			m[k] = s
+		}
+		if e.Amount > 0 {
+			s.Credit += e.Amount
+		} else {
+			s.Debit -= e.Amount
+		}
+		s.Net += e.Amount
+		s.ByType[e.Type] += e.Amount
+	}
+	list := make([]*LedgerSummary, 0, len(m))
+	for _, s := range m {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Cycle != list[j].Cycle {
+			return list[i].Cycle < list[j].Cycle
+		}
+		return list[i].Address.String() < list[j].Address.String()
+	})
+	return list
+}
+
+// WriteJSON writes all entries as JSON array to w.
+func (l *Ledger) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	entries := l.Entries
+	if entries == nil {
+		entries = []*LedgerEntry{}
+	}
+	return enc.Encode(entries)
+}
+
+var ledgerCSVHeader = []string{
+	"time", "height", "cycle", "block", "op_hash", "type", "address", "account",
+	"amount_mutez", "amount", "counterparty", "counteraccount", "kind", "category", "origin",
+}
+
+// WriteCSV writes all entries as CSV with header to w. Amounts are written
+// in mutez and in tez.
+func (l *Ledger) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(ledgerCSVHeader); err != nil {
+		return err
+	}
+	for _, e := range l.Entries {
+		var ts, block, op, cp string
+		if !e.Time.IsZero() {
+			ts = e.Time.UTC().Format(time.RFC3339)
+		}
+		if e.Block.IsValid() {
+			block = e.Block.String()
+		}
+		if e.OpHash.IsValid() {
+			op = e.OpHash.String()
+		}
+		if e.Counterparty.IsValid() {
+			cp = e.Counterparty.String()
+		}
+		err := cw.Write([]string{
+			ts,
+			strconv.FormatInt(e.Height, 10),
+			strconv.FormatInt(e.Cycle, 10),
+			block,
+			op,
+			e.Type.String(),
+			e.Address.String(),
+			e.Account.String(),
+			strconv.FormatInt(e.Amount, 10),
+			tezos.NewZ(e.Amount).Decimals(6),
+			cp,
+			e.Counteraccount,
+			e.Kind,
+			e.Category,
+			e.Origin,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSummaryCSV writes per-cycle summaries as CSV with header to w. Each
+// ledger type becomes a column with the net change in mutez.
+func WriteSummaryCSV(w io.Writer, list []*LedgerSummary) error {
+	cw := csv.NewWriter(w)
+	head := []string{"cycle", "address", "credit", "debit", "net"}
+	for t := LedgerTransfer; t <= LedgerActivation; t++ {
+		head = append(head, t.String())
+	}
+	if err := cw.Write(head); err != nil {
+		return err
+	}
+	for _, s := range list {
+		row := []string{
+			strconv.FormatInt(s.Cycle, 10),
+			s.Address.String(),
+			strconv.FormatInt(s.Credit, 10),
+			strconv.FormatInt(s.Debit, 10),
+			strconv.FormatInt(s.Net, 10),
+		}
+		for t := LedgerTransfer; t <= LedgerActivation; t++ {
+			row = append(row, strconv.FormatInt(s.ByType[t], 10))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
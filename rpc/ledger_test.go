@@ -0,0 +1,175 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+const (
+	ledgerBaker = "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"
+	ledgerUser  = "tz1LmrwzCKUDibk7xaGC5RxvTbmUbCAtCA4a"
+)
+
+func ledgerTestBlock(t *testing.T) *Block {
+	t.Helper()
+	raw := `{"hash":"BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2",
+	"header":{"level":1000,"timestamp":"2026-01-02T03:04:05Z"},
+	"metadata":{"level_info":{"level":1000,"cycle":7},"balance_updates":[
+		{"kind":"accumulator","category":"block fees","change":"-1000","origin":"block"},
+		{"kind":"contract","contract":"` + ledgerBaker + `","change":"1000","origin":"block"},
+		{"kind":"minted","category":"baking rewards","change":"-5000","origin":"block"},
+		{"kind":"freezer","category":"deposits","staker":{"baker_own_stake":"` + ledgerBaker + `"},"change":"5000","origin":"block"},
+		{"kind":"minted","category":"attesting rewards","change":"-200","origin":"block"},
+		{"kind":"contract","contract":"` + ledgerBaker + `","change":"200","origin":"block"}
+	]},
+	"operations":[[],[],[],[{"hash":"oo6JPEAy8VuMRGaFuMmLNFFGdJgiaKfnmT1CpHJfKP3Ye5ZahiP","contents":[
+		{"kind":"transaction","source":"` + ledgerUser + `","fee":"1000","counter":"1","gas_limit":"1000","storage_limit":"100",
+		"amount":"70000","destination":"` + ledgerBaker + `",
+		"metadata":{"balance_updates":[
+			{"kind":"contract","contract":"` + ledgerUser + `","change":"-1000","origin":"block"},
+			{"kind":"accumulator","category":"block fees","change":"1000","origin":"block"}
+		],"operation_result":{"status":"applied","balance_updates":[
+			{"kind":"contract","contract":"` + ledgerUser + `","change":"-70000","origin":"block"},
+			{"kind":"contract","contract":"` + ledgerBaker + `","change":"70000","origin":"block"},
+			{"kind":"contract","contract":"` + ledgerUser + `","change":"-64250","origin":"block"},
+			{"kind":"burned","category":"storage fees","change":"64250","origin":"block"}
+		]}}},
+		{"kind":"transaction","source":"` + ledgerUser + `","fee":"0","counter":"2","gas_limit":"1000","storage_limit":"0",
+		"amount":"300000","destination":"` + ledgerUser + `","parameters":{"entrypoint":"stake","value":{"prim":"Unit"}},
+		"metadata":{"balance_updates":[],"operation_result":{"status":"applied","balance_updates":[
+			{"kind":"contract","contract":"` + ledgerUser + `","change":"-300000","origin":"block"},
+			{"kind":"freezer","category":"deposits","staker":{"contract":"` + ledgerUser + `","delegate":"` + ledgerBaker + `"},"change":"300000","origin":"block"},
+			{"kind":"staking","category":"staking delegator numerator","delegator":"` + ledgerUser + `","change":"300000","origin":"block"}
+		]}}}
+	]}]]}`
+	b := &Block{}
+	decodeTestJSON(t, raw, b)
+	return b
+}
+
+func TestLedger(t *testing.T) {
+	b := ledgerTestBlock(t)
+	l := NewLedger()
+	l.AddBlock(b)
+
+	type row struct {
+		Type    LedgerType
+		Addr    string
+		Account LedgerAccount
+		Amount  int64
+		Counter string
+	}
+	var rows []row
+	for _, e := range l.Entries {
+		rows = append(rows, row{e.Type, e.Address.String(), e.Account, e.Amount, e.Counteraccount})
+		assert.Equal(t, int64(1000), e.Height)
+		assert.Equal(t, int64(7), e.Cycle)
+	}
+	assert.Equal(t, []row{
+		{LedgerFee, ledgerBaker, AccountSpendable, 1000, "accumulator block fees"},
+		{LedgerReward, ledgerBaker, AccountStaked, 5000, "minted baking rewards"},
+		{LedgerReward, ledgerBaker, AccountSpendable, 200, "minted attesting rewards"},
+		{LedgerFee, ledgerUser, AccountSpendable, -1000, "accumulator block fees"},
+		{LedgerTransfer, ledgerUser, AccountSpendable, -70000, "spendable"},
+		{LedgerTransfer, ledgerBaker, AccountSpendable, 70000, "spendable"},
+		{LedgerBurn, ledgerUser, AccountSpendable, -64250, "burned storage fees"},
+		{LedgerStake, ledgerUser, AccountSpendable, -300000, "staked"},
+		{LedgerStake, ledgerUser, AccountStaked, 300000, "spendable"},
+	}, rows)
+	assert.False(t, l.Entries[0].OpHash.IsValid())
+	assert.True(t, l.Entries[3].OpHash.IsValid())
+	assert.Equal(t, ledgerBaker, l.Entries[4].Counterparty.String())
+
+	// address filter
+	l2 := NewLedger(tezos.MustParseAddress(ledgerUser))
+	l2.AddBlock(b)
+	assert.Len(t, l2.Entries, 5)
+
+	// per-cycle aggregation
+	sum := l.Summary()
+	require.Len(t, sum, 2)
+	byAddr := map[string]*LedgerSummary{}
+	for _, s := range sum {
+		assert.Equal(t, int64(7), s.Cycle)
+		byAddr[s.Address.String()] = s
+	}
+	baker := byAddr[ledgerBaker]
+	assert.Equal(t, int64(76200), baker.Credit)
+	assert.Equal(t, int64(0), baker.Debit)
+	assert.Equal(t, int64(5200), baker.ByType[LedgerReward])
+	user := byAddr[ledgerUser]
+	assert.Equal(t, int64(-135250), user.Net)
+	assert.Equal(t, int64(0), user.ByType[LedgerStake])
+	assert.Equal(t, int64(-64250), user.ByType[LedgerBurn])
+}
+
+func TestLedgerSlash(t *testing.T) {
+	raw := `[
+		{"kind":"freezer","category":"deposits","staker":{"baker_own_stake":"` + ledgerBaker + `"},"change":"-4000","origin":"block"},
+		{"kind":"burned","category":"punishments","change":"4000","origin":"block"},
+		{"kind":"minted","category":"attesting rewards","change":"-200","origin":"block"},
+		{"kind":"burned","category":"lost attesting rewards","delegate":"` + ledgerBaker + `","participation":false,"revelation":false,"change":"200","origin":"block"},
+		{"kind":"contract","contract":"` + ledgerUser + `","change":"-300","origin":"block"},
+		{"kind":"burned","category":"storage fees","change":"300","origin":"block"}
+	]`
+	var list BalanceUpdates
+	decodeTestJSON(t, raw, &list)
+
+	l := NewLedger()
+	l.add(ledgerPos{}, list)
+	type row struct {
+		Type    LedgerType
+		Addr    string
+		Amount  int64
+		Counter string
+	}
+	var rows []row
+	for _, e := range l.Entries {
+		rows = append(rows, row{e.Type, e.Address.String(), e.Amount, e.Counteraccount})
+	}
+	// lost rewards never reach an owned account and create no entry
+	assert.Equal(t, []row{
+		{LedgerSlash, ledgerBaker, -4000, "burned punishments"},
+		{LedgerBurn, ledgerUser, -300, "burned storage fees"},
+	}, rows)
+}
+
+func TestLedgerExport(t *testing.T) {
+	l := NewLedger()
+	l.AddBlock(ledgerTestBlock(t))
+
+	var buf bytes.Buffer
+	require.NoError(t, l.WriteCSV(&buf))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, len(l.Entries)+1)
+	assert.Equal(t, ledgerCSVHeader, records[0])
+	assert.Equal(t, []string{
+		"2026-01-02T03:04:05Z", "1000", "7", "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2", "",
+		"fee", ledgerBaker, "spendable", "1000", "0.001000", "", "accumulator block fees", "contract", "", "block",
+	}, records[1][:15])
+	assert.Equal(t, "-0.064250", records[7][9])
+
+	buf.Reset()
+	require.NoError(t, l.WriteJSON(&buf))
+	var entries []*LedgerEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entries))
+	require.Len(t, entries, len(l.Entries))
+	assert.Equal(t, l.Entries[8], entries[8])
+
+	buf.Reset()
+	require.NoError(t, WriteSummaryCSV(&buf, l.Summary()))
+	records, err = csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"cycle", "address", "credit", "debit", "net", "transfer"}, records[0][:6])
+}
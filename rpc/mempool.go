@@ -6,6 +6,9 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"time"
+
+	"github.com/trilitech/tzgo/tezos"
 )
 
 // Mempool represents mempool operations
@@ -18,6 +21,67 @@ type Mempool struct {
 	Unprocessed   []*Operation `json:"unprocessed"`
 }
 
+// MempoolStatus is the classification of an operation in the node's mempool.
+type MempoolStatus byte
+
+const (
+	MempoolValidated MempoolStatus = iota
+	MempoolBranchDelayed
+	MempoolBranchRefused
+	MempoolRefused
+	MempoolOutdated
+)
+
+func (s MempoolStatus) String() string {
+	switch s {
+	case MempoolValidated:
+		return "validated"
+	case MempoolBranchDelayed:
+		return "branch_delayed"
+	case MempoolBranchRefused:
+		return "branch_refused"
+	case MempoolRefused:
+		return "refused"
+	case MempoolOutdated:
+		return "outdated"
+	default:
+		return "unknown"
+	}
+}
+
+// IsRefused returns true when the operation can never be included on the
+// current branch.
+func (s MempoolStatus) IsRefused() bool {
+	return s == MempoolBranchRefused || s == MempoolRefused || s == MempoolOutdated
+}
+
+// ParseMempoolStatus classifies a mempool operation by the error category
+// of its first error. Operations without errors are validated.
+func ParseMempoolStatus(errs []OperationError) MempoolStatus {
+	if len(errs) == 0 {
+		return MempoolValidated
+	}
+	switch errs[0].Kind {
+	case "temporary":
+		return MempoolBranchDelayed
+	case "branch":
+		return MempoolBranchRefused
+	case "outdated":
+		return MempoolOutdated
+	default:
+		return MempoolRefused
+	}
+}
+
+// MempoolEvent reports a mempool classification of a watched operation.
+type MempoolEvent struct {
+	Hash   tezos.OpHash     `json:"hash"`
+	Status MempoolStatus    `json:"status"`
+	Errors []OperationError `json:"errors,omitempty"`
+	Op     *Operation       `json:"-"`
+	Time   time.Time        `json:"time"`
+}
+
 // GetMempool returns mempool pending operations
 func (c *Client) GetMempool(ctx context.Context) (*Mempool, error) {
 	var mem Mempool
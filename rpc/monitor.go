@@ -480,6 +480,13 @@ func (c *Client) MonitorMempool(ctx context.Context, monitor *MempoolMonitor) er
 	return c.GetAsync(ctx, "chains/main/mempool/monitor_operations", monitor)
 }
 
+// MonitorMempoolAll is like MonitorMempool but streams operations of all
+// classifications including branch_delayed, branch_refused, refused and
+// outdated operations together with their errors.
+func (c *Client) MonitorMempoolAll(ctx context.Context, monitor *MempoolMonitor) error {
+	return c.GetAsync(ctx, "chains/main/mempool/monitor_operations?validated=true&branch_delayed=true&branch_refused=true&refused=true&outdated=true", monitor)
+}
+
 // MonitorNetworkPointLog monitors network events related to an `IP:addr`.
 // https://tezos.gitlab.io/mainnet/api/rpc.html#get-network-peers-peer-id-log
 func (c *Client) MonitorNetworkPointLog(ctx context.Context, address string, monitor *NetworkPointMonitor) error {
@@ -5,6 +5,7 @@ package rpc
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -16,21 +17,51 @@ import (
 // TODO:
 // - support AdressObserver with address subscription filter
 // - disable events/polling when no subscriber exists
-// - handle reorgs (inclusion may switch to a different block hash)
+
+// DefaultObserverDepth is the number of recent blocks an observer keeps
+// for detecting reorgs and back-filling missed levels.
+const DefaultObserverDepth = 64
+
+var errObserverGap = errors.New("monitor: no common ancestor within depth")
 
 type ObserverCallback func(*BlockHeaderLogEntry, int64, int, int, bool) bool
 
+// ObserverReorgCallback is called when the block that included a matched
+// operation was removed from the canonical chain. The subscription is
+// reset and matches again when the operation is included on the new
+// branch. Return true to remove the subscription.
+type ObserverReorgCallback func(*BlockHeaderLogEntry) bool
+
+// MempoolCallback is called when a mempool observer sees a watched operation
+// with a new classification. Return true to remove the subscription.
+type MempoolCallback func(*MempoolEvent) bool
+
 type observerSubscription struct {
 	id      int
 	cb      ObserverCallback
+	reorg   ObserverReorgCallback
+	mempool MempoolCallback
+	status  MempoolStatus // last mempool status seen
+	seen    bool          // mempool status was reported
 	oh      tezos.OpHash
 	matched bool
+	block   tezos.BlockHash // inclusion block when matched
+	height  int64           // inclusion height when matched
+}
+
+type observerMatch struct {
+	block tezos.BlockHash
+	level int64
+	list  int
+	pos   int
 }
 
 type Observer struct {
 	subs     map[int]*observerSubscription
 	watched  map[tezos.OpHash][]int
-	recent   map[tezos.OpHash][3]int64
+	recent   map[tezos.OpHash]observerMatch
+	chain    []*BlockHeaderLogEntry // recent canonical blocks in level order
+	depth    int
 	seq      int
 	once     sync.Once
 	mu       sync.Mutex
@@ -46,7 +77,8 @@ func NewObserver() *Observer {
 	m := &Observer{
 		subs:     make(map[int]*observerSubscription),
 		watched:  make(map[tezos.OpHash][]int),
-		recent:   make(map[tezos.OpHash][3]int64),
+		recent:   make(map[tezos.OpHash]observerMatch),
+		depth:    DefaultObserverDepth,
 		minDelay: tezos.DefaultParams.MinimalBlockDelay,
 		ctx:      ctx,
 		cancel:   cancel,
@@ -61,42 +93,92 @@ func (m *Observer) Head() *BlockHeaderLogEntry {
 	return m.head
 }
 
+// finalLevel returns the level of the most recent final block or -1 when
+// no head was observed yet.
+func (m *Observer) finalLevel() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.head == nil {
+		return -1
+	}
+	return m.head.Level - FinalityDepth
+}
+
 func (m *Observer) WithDelay(minDelay time.Duration) *Observer {
 	m.minDelay = minDelay
 	return m
 }
 
+// WithDepth sets the max number of blocks the observer walks back to
+// back-fill missed levels or find the common ancestor after a reorg.
+func (m *Observer) WithDepth(n int) *Observer {
+	if n > 0 {
+		m.depth = n
+	}
+	return m
+}
+
 func (m *Observer) Close() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.cancel()
 	m.subs = make(map[int]*observerSubscription)
 	m.watched = make(map[tezos.OpHash][]int)
-	m.recent = make(map[tezos.OpHash][3]int64)
+	m.recent = make(map[tezos.OpHash]observerMatch)
 }
 
 func (m *Observer) Subscribe(oh tezos.OpHash, cb ObserverCallback) int {
+	return m.SubscribeReorg(oh, cb, nil)
+}
+
+// SubscribeReorg subscribes to inclusion of operation oh like Subscribe
+// and additionally calls onReorg when a matched inclusion block is
+// orphaned.
+func (m *Observer) SubscribeReorg(oh tezos.OpHash, cb ObserverCallback, onReorg ObserverReorgCallback) int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.seq++
 	seq := m.seq
-	m.subs[seq] = &observerSubscription{
-		id: seq,
-		cb: cb,
-		oh: oh,
+	sub := &observerSubscription{
+		id:    seq,
+		cb:    cb,
+		reorg: onReorg,
+		oh:    oh,
 	}
+	m.subs[seq] = sub
 	if pos, ok := m.recent[oh]; ok {
-		match := m.subs[seq]
 		m.c.Log.Debugf("monitor: %03d direct match %s", seq, oh)
-		if remove := match.cb(m.head, pos[0], int(pos[1]), int(pos[2]), false); remove {
-			delete(m.subs, match.id)
+		if remove := sub.cb(m.head, pos.level, pos.list, pos.pos, false); remove {
+			delete(m.subs, sub.id)
+			return seq
 		}
+		sub.matched = true
+		sub.block = pos.block
+		sub.height = pos.level
 	}
 	m.c.Log.Debugf("monitor: %03d subscribed %s", seq, oh)
 	m.watched[oh] = append(m.watched[oh], seq)
 	return seq
 }
 
+// SubscribeMempool registers cb for mempool events of operation oh. Use
+// tezos.ZeroOpHash to receive events for all operations. Callbacks only
+// fire on observers started with ListenMempool and are called once per
+// classification change.
+func (m *Observer) SubscribeMempool(oh tezos.OpHash, cb MempoolCallback) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seq++
+	seq := m.seq
+	m.subs[seq] = &observerSubscription{
+		id:      seq,
+		mempool: cb,
+		oh:      oh,
+	}
+	m.watched[oh] = append(m.watched[oh], seq)
+	return seq
+}
+
 func (m *Observer) Unsubscribe(id int) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -144,7 +226,84 @@ func (m *Observer) ListenMempool(cli *Client) {
 }
 
 func (m *Observer) listenMempool() {
-	// TODO
+	var mon *MempoolMonitor
+	defer func() {
+		if mon != nil {
+			mon.Close()
+		}
+	}()
+
+	for {
+		// handle close request
+		select {
+		case <-m.ctx.Done():
+			return
+		default:
+		}
+
+		// (re)connect, the node resets the stream on every new head
+		if mon == nil {
+			mon = NewMempoolMonitor()
+			if err := m.c.MonitorMempoolAll(m.ctx, mon); err != nil {
+				mon.Close()
+				mon = nil
+				// wait 5 sec, but also return on close
+				select {
+				case <-m.ctx.Done():
+					return
+				case <-time.After(5 * time.Second):
+				}
+				continue
+			}
+		}
+
+		ops, err := mon.Recv(m.ctx)
+		if err != nil {
+			mon.Close()
+			mon = nil
+			continue
+		}
+		m.processMempool(ops)
+	}
+}
+
+// processMempool fans out mempool operations to subscribers.
+func (m *Observer) processMempool(ops []*Operation) {
+	now := time.Now().UTC()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, op := range ops {
+		ev := &MempoolEvent{
+			Hash:   op.Hash,
+			Status: ParseMempoolStatus(op.Errors),
+			Errors: op.Errors,
+			Op:     op,
+			Time:   now,
+		}
+		for _, oh := range []tezos.OpHash{op.Hash, tezos.ZeroOpHash} {
+			var removed []*observerSubscription
+			for _, id := range m.watched[oh] {
+				sub, ok := m.subs[id]
+				if !ok || sub.mempool == nil {
+					continue
+				}
+				// streams repeat known operations after reconnect
+				if oh.IsValid() && sub.seen && sub.status == ev.Status {
+					continue
+				}
+				sub.seen = true
+				sub.status = ev.Status
+				m.c.Log.Debugf("monitor: %03d mempool %s %s", sub.id, ev.Status, op.Hash)
+				if sub.mempool(ev) {
+					delete(m.subs, sub.id)
+					removed = append(removed, sub)
+				}
+			}
+			for _, sub := range removed {
+				m.removeWatcher(sub.oh, sub.id)
+			}
+		}
+	}
 }
 
 func (m *Observer) listenBlocks() {
@@ -217,7 +376,7 @@ func (m *Observer) listenBlocks() {
 		firstLoop = false
 
 		// skip already processed blocks
-		if head.Hash.Equal(m.head.Hash) && !useEvents {
+		if m.isKnown(head) {
 			// wait minDelay/2 for late blocks
 			if !useEvents {
 				select {
@@ -230,106 +389,290 @@ func (m *Observer) listenBlocks() {
 		}
 		m.c.Log.Debugf("monitor: new block %d %s", head.Level, head.Hash)
 
-		// TODO: check for reorg and gaps
-
-		// handle block watchers
-		m.mu.Lock()
-		for _, id := range m.watched[tezos.ZeroOpHash] {
-			sub, ok := m.subs[id]
-			if !ok {
-				m.removeWatcher(tezos.ZeroOpHash, id)
-				continue
+		// check for reorg and gaps
+		branch, ancestor, err := m.resolve(head)
+		switch {
+		case err == nil:
+			if ancestor < m.head.Level {
+				m.rollback(ancestor)
 			}
-			if remove := sub.cb(head, head.Level, -1, -1, false); remove {
-				delete(m.subs, id)
-				m.removeWatcher(tezos.ZeroOpHash, id)
+		case errors.Is(err, errObserverGap):
+			m.c.Log.Warnf("monitor: %v, restarting at block %d", err, branch[0].Level)
+			m.restart(branch)
+		default:
+			m.c.Log.Warnf("monitor: cannot fetch block header: %v", err)
+			continue
+		}
+
+		// process the new branch in level order
+		for _, b := range branch {
+			if err := m.processBlock(b); err != nil {
+				m.c.Log.Warnf("monitor: cannot fetch block ops: %v", err)
+				break
 			}
 		}
 
-		// callback for all previous matches who have not yet unregistered (i.e. waiting for
-		// additional confirmations)
-		for _, v := range m.subs {
-			if v.matched {
-				m.c.Log.Debugf("monitor: signal n-th match for %d %s", v.id, v.oh)
-				if remove := v.cb(head, head.Level, -1, -1, false); remove {
-					delete(m.subs, v.id)
-					m.removeWatcher(v.oh, v.id)
-				}
+		// wait in poll mode
+		if !useEvents {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-time.After(m.minDelay):
 			}
 		}
-		// clear recent op hashes
-		for n := range m.recent {
-			delete(m.recent, n)
+	}
+}
+
+// isKnown returns true when block is part of the observed canonical chain.
+func (m *Observer) isKnown(block *BlockHeaderLogEntry) bool {
+	b := m.block(block.Level)
+	return b != nil && b.Hash.Equal(block.Hash)
+}
+
+// block returns the observed canonical block at level or nil.
+func (m *Observer) block(level int64) *BlockHeaderLogEntry {
+	if len(m.chain) == 0 {
+		return nil
+	}
+	i := level - m.chain[0].Level
+	if i < 0 || i >= int64(len(m.chain)) {
+		return nil
+	}
+	return m.chain[i]
+}
+
+// resolve connects head to the observed chain by walking predecessor
+// hashes. It returns the blocks from the common ancestor (exclusive) to
+// head in level order and the ancestor's level. Missing blocks are
+// fetched from the node.
+func (m *Observer) resolve(head *BlockHeaderLogEntry) ([]*BlockHeaderLogEntry, int64, error) {
+	branch := []*BlockHeaderLogEntry{head}
+	if len(m.chain) == 0 {
+		return branch, head.Level - 1, nil
+	}
+	var (
+		cur      = head
+		ancestor int64
+		err      error
+	)
+	for {
+		parent := cur.Level - 1
+		if b := m.block(parent); b != nil && b.Hash.Equal(cur.Predecessor) {
+			ancestor = parent
+			break
+		}
+		if parent < m.chain[0].Level || len(branch) >= m.depth {
+			err = errObserverGap
+			break
+		}
+		h, ferr := m.c.GetBlockHeader(m.ctx, cur.Predecessor)
+		if ferr != nil {
+			return nil, 0, ferr
+		}
+		cur = h.LogEntry()
+		branch = append(branch, cur)
+	}
+	for i, j := 0, len(branch)-1; i < j; i, j = i+1, j-1 {
+		branch[i], branch[j] = branch[j], branch[i]
+	}
+	return branch, ancestor, err
+}
+
+// rollback removes all blocks above ancestor from the observed chain and
+// resets subscriptions matched in orphaned blocks.
+func (m *Observer) rollback(ancestor int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	orphans := make(map[int64]*BlockHeaderLogEntry)
+	for _, b := range m.chain {
+		if b.Level > ancestor {
+			orphans[b.Level] = b
+		}
+	}
+	m.c.Log.Infof("monitor: reorg of %d blocks at level %d", len(orphans), ancestor+1)
+	if b := m.block(ancestor); b != nil {
+		m.chain = m.chain[:ancestor-m.chain[0].Level+1]
+		m.head = b
+	}
+	for n := range m.recent {
+		delete(m.recent, n)
+	}
+	for _, sub := range m.subs {
+		if !sub.matched || sub.height <= ancestor {
+			continue
 		}
+		orphan, ok := orphans[sub.height]
+		if !ok {
+			orphan = &BlockHeaderLogEntry{Hash: sub.block, Level: sub.height}
+		}
+		m.orphan(sub, orphan)
+	}
+}
 
-		numSubs := len(m.subs)
-		m.mu.Unlock()
+// restart drops the observed chain when head cannot be connected to it
+// and resets subscriptions whose inclusion block is no longer canonical.
+// Inclusion blocks are checked against branch or fetched from the node.
+func (m *Observer) restart(branch []*BlockHeaderLogEntry) {
+	type match struct {
+		id     int
+		block  tezos.BlockHash
+		height int64
+	}
+	var matches []match
+	m.mu.Lock()
+	for _, sub := range m.subs {
+		if sub.matched {
+			matches = append(matches, match{sub.id, sub.block, sub.height})
+		}
+	}
+	m.mu.Unlock()
+
+	first := branch[0].Level
+	stale := make(map[int]bool)
+	for _, v := range matches {
+		var canonical tezos.BlockHash
+		if i := v.height - first; i >= 0 && i < int64(len(branch)) {
+			canonical = branch[i].Hash
+		} else if h, err := m.c.GetBlockHeader(m.ctx, BlockLevel(v.height)); err == nil {
+			canonical = h.Hash
+		}
+		if !canonical.Equal(v.block) {
+			stale[v.id] = true
+		}
+	}
 
-		// pull block ops when subs exist
-		var (
-			ohs [][]tezos.OpHash
-			err error
-		)
-		if numSubs > 0 {
-			ohs, err = m.c.GetBlockOperationHashes(m.ctx, head.Hash)
-			if err != nil {
-				m.c.Log.Warnf("monitor: cannot fetch block ops: %v", err)
-				continue
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.chain = m.chain[:0]
+	for n := range m.recent {
+		delete(m.recent, n)
+	}
+	for _, v := range matches {
+		sub, ok := m.subs[v.id]
+		if !ok || !stale[v.id] || !sub.matched || !sub.block.Equal(v.block) {
+			continue
+		}
+		m.orphan(sub, &BlockHeaderLogEntry{Hash: sub.block, Level: sub.height})
+	}
+}
+
+// orphan resets a subscription matched in a block that left the canonical
+// chain. Caller must hold the lock.
+func (m *Observer) orphan(sub *observerSubscription, block *BlockHeaderLogEntry) {
+	m.c.Log.Debugf("monitor: %03d orphaned match %s in block %d %s", sub.id, sub.oh, sub.height, sub.block)
+	sub.matched = false
+	sub.block = tezos.ZeroBlockHash
+	sub.height = 0
+	if sub.reorg != nil && sub.reorg(block) {
+		delete(m.subs, sub.id)
+		m.removeWatcher(sub.oh, sub.id)
+	}
+}
+
+// processBlock signals block watchers and confirmations, matches the
+// block's operations against subscriptions and appends the block to the
+// observed chain.
+func (m *Observer) processBlock(head *BlockHeaderLogEntry) error {
+	// handle block watchers
+	m.mu.Lock()
+	for _, id := range m.watched[tezos.ZeroOpHash] {
+		sub, ok := m.subs[id]
+		if !ok {
+			m.removeWatcher(tezos.ZeroOpHash, id)
+			continue
+		}
+		if sub.cb == nil {
+			continue
+		}
+		if remove := sub.cb(head, head.Level, -1, -1, false); remove {
+			delete(m.subs, id)
+			m.removeWatcher(tezos.ZeroOpHash, id)
+		}
+	}
+
+	// callback for all previous matches who have not yet unregistered (i.e. waiting for
+	// additional confirmations)
+	for _, v := range m.subs {
+		if v.matched {
+			m.c.Log.Debugf("monitor: signal n-th match for %d %s", v.id, v.oh)
+			if remove := v.cb(head, head.Level, -1, -1, false); remove {
+				delete(m.subs, v.id)
+				m.removeWatcher(v.oh, v.id)
 			}
 		}
+	}
+	// clear recent op hashes
+	for n := range m.recent {
+		delete(m.recent, n)
+	}
 
-		// fan-out matches
-		m.mu.Lock()
-		for l, list := range ohs {
-			for n, h := range list {
-				// keep as recent
-				m.recent[h] = [3]int64{head.Level, int64(l), int64(n)}
+	numSubs := len(m.subs)
+	m.mu.Unlock()
 
-				// match op hash against subs
-				ids, ok := m.watched[h]
-				if !ok {
-					m.c.Log.Debugf("monitor: --- !! %s", h)
-					continue
-				}
+	// pull block ops when subs exist
+	var (
+		ohs [][]tezos.OpHash
+		err error
+	)
+	if numSubs > 0 {
+		ohs, err = m.c.GetBlockOperationHashes(m.ctx, head.Hash)
+		if err != nil {
+			return err
+		}
+	}
 
-				// handle all subscriptions for this op hash
-				var removed []*observerSubscription
-				for _, id := range ids {
-					sub, ok := m.subs[id]
-					if !ok {
-						m.c.Log.Debugf("monitor: --- !! %s", h)
-						continue
-					}
+	// fan-out matches
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for l, list := range ohs {
+		for n, h := range list {
+			// keep as recent
+			m.recent[h] = observerMatch{head.Hash, head.Level, l, n}
 
-					m.c.Log.Debugf("monitor: matched %d %s", sub.id, sub.oh)
+			// match op hash against subs
+			ids, ok := m.watched[h]
+			if !ok {
+				m.c.Log.Debugf("monitor: --- !! %s", h)
+				continue
+			}
 
-					// callback
-					if remove := sub.cb(head, head.Level, l, n, false); remove {
-						delete(m.subs, sub.id)
-						removed = append(removed, sub)
-					} else {
-						sub.matched = true
-					}
+			// handle all subscriptions for this op hash
+			var removed []*observerSubscription
+			for _, id := range ids {
+				sub, ok := m.subs[id]
+				if !ok || sub.cb == nil {
+					m.c.Log.Debugf("monitor: --- !! %s", h)
+					continue
 				}
 
-				// remove deleted subs from watch list
-				for _, sub := range removed {
-					m.removeWatcher(sub.oh, sub.id)
+				m.c.Log.Debugf("monitor: matched %d %s", sub.id, sub.oh)
+
+				// callback
+				if remove := sub.cb(head, head.Level, l, n, false); remove {
+					delete(m.subs, sub.id)
+					removed = append(removed, sub)
+				} else {
+					sub.matched = true
+					sub.block = head.Hash
+					sub.height = head.Level
 				}
 			}
-		}
-
-		// update monitor state
-		m.head = head
-		m.mu.Unlock()
 
-		// wait in poll mode
-		if !useEvents {
-			select {
-			case <-m.ctx.Done():
-				return
-			case <-time.After(m.minDelay):
+			// remove deleted subs from watch list
+			for _, sub := range removed {
+				m.removeWatcher(sub.oh, sub.id)
 			}
 		}
 	}
+
+	// update monitor state
+	m.head = head
+	if len(m.chain) > 0 && m.chain[len(m.chain)-1].Level+1 != head.Level {
+		m.chain = m.chain[:0]
+	}
+	m.chain = append(m.chain, head)
+	if len(m.chain) > m.depth {
+		m.chain = append(m.chain[:0], m.chain[len(m.chain)-m.depth:]...)
+	}
+	return nil
 }
@@ -0,0 +1,325 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// testChain serves a mutable chain of blocks over the poll-mode RPCs.
+type testChain struct {
+	sync.Mutex
+	blocks   map[tezos.BlockHash]*testBlock
+	head     *testBlock
+	injected []tezos.OpHash
+	refuse   bool // reject injections
+}
+
+type testBlock struct {
+	hash  tezos.BlockHash
+	pred  tezos.BlockHash
+	level int64
+	ops   []tezos.OpHash
+}
+
+func newTestChain(t *testing.T) (*testChain, *Client) {
+	c := &testChain{blocks: make(map[tezos.BlockHash]*testBlock)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Lock()
+		defer c.Unlock()
+		if r.URL.Path == "/injection/operation" {
+			if c.refuse {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`[{"kind":"temporary","id":"failure","msg":"Error while applying operation"}]`))
+				return
+			}
+			var body string
+			json.NewDecoder(r.Body).Decode(&body)
+			buf, _ := hex.DecodeString(body)
+			d := tezos.Digest(buf)
+			oh := tezos.NewOpHash(d[:])
+			c.injected = append(c.injected, oh)
+			json.NewEncoder(w).Encode(oh)
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/chains/main/blocks/")
+		parts := strings.Split(path, "/")
+		if len(parts) > 2 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		b := c.lookup(parts[0])
+		if b == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if len(parts) == 1 {
+			json.NewEncoder(w).Encode(map[string]any{
+				"hash": b.hash,
+				"header": map[string]any{
+					"level":       b.level,
+					"predecessor": b.pred,
+				},
+			})
+			return
+		}
+		switch parts[1] {
+		case "header":
+			json.NewEncoder(w).Encode(map[string]any{
+				"hash":        b.hash,
+				"level":       b.level,
+				"predecessor": b.pred,
+			})
+		case "operation_hashes":
+			json.NewEncoder(w).Encode([][]tezos.OpHash{{}, {}, {}, b.ops})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	cli, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+	return c, cli
+}
+
+// lookup resolves head, a level on the canonical chain or a block hash.
+func (c *testChain) lookup(id string) *testBlock {
+	if id == "head" {
+		return c.head
+	}
+	if level, err := strconv.ParseInt(id, 10, 64); err == nil {
+		b := c.head
+		for b != nil && b.level > level {
+			b = c.blocks[b.pred]
+		}
+		if b == nil || b.level != level {
+			return nil
+		}
+		return b
+	}
+	h, err := tezos.ParseBlockHash(id)
+	if err != nil {
+		return nil
+	}
+	return c.blocks[h]
+}
+
+// add appends a block on top of parent and makes it the new head.
+func (c *testChain) add(parent *testBlock, seed byte, ops ...tezos.OpHash) *testBlock {
+	c.Lock()
+	defer c.Unlock()
+	b := &testBlock{
+		hash:  tezos.NewBlockHash(append(make([]byte, 31), seed)),
+		level: 1,
+		ops:   ops,
+	}
+	if parent != nil {
+		b.pred = parent.hash
+		b.level = parent.level + 1
+	}
+	c.blocks[b.hash] = b
+	c.head = b
+	return b
+}
+
+func TestObserverReorgAndGaps(t *testing.T) {
+	chain, cli := newTestChain(t)
+	oh := tezos.NewOpHash(append(make([]byte, 31), 0x42))
+
+	type event struct {
+		level  int64
+		list   int
+		reorg  bool
+		orphan tezos.BlockHash
+	}
+	var (
+		mu     sync.Mutex
+		events []event
+	)
+	last := func() []event {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]event(nil), events...)
+	}
+
+	a1 := chain.add(nil, 1)
+	obs := NewObserver().WithDelay(10 * time.Millisecond)
+	obs.c = cli
+	obs.SubscribeReorg(oh, func(_ *BlockHeaderLogEntry, level int64, list, _ int, _ bool) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event{level: level, list: list})
+		return false
+	}, func(b *BlockHeaderLogEntry) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event{level: b.Level, reorg: true, orphan: b.Hash})
+		return false
+	})
+	obs.Listen(cli)
+	defer obs.Close()
+	require.Eventually(t, func() bool { return obs.isKnownLocked(a1) }, time.Second, 5*time.Millisecond)
+
+	// skip levels: 2 and 3 are back-filled, op is matched in block 2
+	a2 := chain.add(a1, 2, oh)
+	a3 := chain.add(a2, 3)
+	a4 := chain.add(a3, 4)
+	require.Eventually(t, func() bool { return len(last()) == 3 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []event{{2, 3, false, tezos.ZeroBlockHash}, {3, -1, false, tezos.ZeroBlockHash}, {4, -1, false, tezos.ZeroBlockHash}}, last())
+
+	// switch branch at level 2, op is included again at level 3
+	b2 := chain.add(a1, 0x12)
+	b3 := chain.add(b2, 0x13, oh)
+	require.Eventually(t, func() bool { return len(last()) == 5 }, time.Second, 5*time.Millisecond)
+	ev := last()
+	assert.Equal(t, event{2, 0, true, a2.hash}, ev[3])
+	assert.Equal(t, event{3, 3, false, tezos.ZeroBlockHash}, ev[4])
+	assert.True(t, obs.isKnownLocked(b3))
+	assert.Equal(t, a4.level-1, b3.level)
+}
+
+func TestObserverGapReorg(t *testing.T) {
+	oh := tezos.NewOpHash(append(make([]byte, 31), 0x42))
+	for _, orphaned := range []bool{true, false} {
+		chain, cli := newTestChain(t)
+		var (
+			mu     sync.Mutex
+			events []int64 // inclusion levels, negative for reorgs
+		)
+		last := func() []int64 {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]int64(nil), events...)
+		}
+		a1 := chain.add(nil, 1)
+		obs := NewObserver().WithDelay(10 * time.Millisecond).WithDepth(2)
+		obs.c = cli
+		obs.SubscribeReorg(oh, func(_ *BlockHeaderLogEntry, level int64, list, _ int, _ bool) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if list >= 0 {
+				events = append(events, level)
+			}
+			return false
+		}, func(b *BlockHeaderLogEntry) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, -b.Level)
+			return false
+		})
+		obs.Listen(cli)
+		require.Eventually(t, func() bool { return obs.isKnownLocked(a1) }, time.Second, 5*time.Millisecond)
+		a2 := chain.add(a1, 2, oh)
+		require.Eventually(t, func() bool { return obs.isKnownLocked(a2) }, time.Second, 5*time.Millisecond)
+		a3 := chain.add(a2, 3)
+		require.Eventually(t, func() bool { return obs.isKnownLocked(a3) }, time.Second, 5*time.Millisecond)
+
+		// jump beyond the observer depth, on a new branch when orphaned
+		parent, seed := a3, byte(0x20)
+		if orphaned {
+			parent, seed = a1, 0x10
+		}
+		head := chain.extend(parent, seed, int(6-parent.level))
+		require.Eventually(t, func() bool { return obs.isKnownLocked(head) }, time.Second, 5*time.Millisecond)
+		if orphaned {
+			assert.Equal(t, []int64{2, -2}, last())
+		} else {
+			assert.Equal(t, []int64{2}, last())
+		}
+		obs.Close()
+	}
+}
+
+// extend appends n blocks on top of parent at once and makes the last one
+// the new head.
+func (c *testChain) extend(parent *testBlock, seed byte, n int) *testBlock {
+	c.Lock()
+	defer c.Unlock()
+	for i := 0; i < n; i++ {
+		b := &testBlock{
+			hash:  tezos.NewBlockHash(append(make([]byte, 31), seed+byte(i))),
+			pred:  parent.hash,
+			level: parent.level + 1,
+		}
+		c.blocks[b.hash] = b
+		parent = b
+	}
+	c.head = parent
+	return parent
+}
+
+// isKnownLocked is a test helper reading observer state under lock.
+func (m *Observer) isKnownLocked(b *testBlock) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.head.Hash.Equal(b.hash)
+}
+
+func TestObserverMempool(t *testing.T) {
+	validated := tezos.NewOpHash(append(make([]byte, 31), 0x01))
+	refused := tezos.NewOpHash(append(make([]byte, 31), 0x02))
+	var conns int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chains/main/mempool/monitor_operations" || r.URL.Query().Get("refused") != "true" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"hash": validated},
+			{"hash": refused, "error": []map[string]any{{"kind": "permanent", "id": "proto.alpha.contract.balance_too_low"}}},
+		})
+		// block until the second connection, then the stream resets
+		if atomic.AddInt32(&conns, 1) > 1 {
+			<-r.Context().Done()
+		}
+	}))
+	t.Cleanup(server.Close)
+	cli, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+
+	var (
+		mu     sync.Mutex
+		events []MempoolEvent
+	)
+	obs := NewObserver()
+	obs.c = cli
+	for _, oh := range []tezos.OpHash{validated, refused} {
+		obs.SubscribeMempool(oh, func(ev *MempoolEvent) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, *ev)
+			return false
+		})
+	}
+	obs.ListenMempool(cli)
+	defer obs.Close()
+
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&conns) > 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	// events are not repeated after reconnect
+	require.Len(t, events, 2)
+	assert.Equal(t, MempoolValidated, events[0].Status)
+	assert.Equal(t, MempoolRefused, events[1].Status)
+	assert.True(t, events[1].Status.IsRefused())
+	require.Len(t, events[1].Errors, 1)
+	assert.Equal(t, "proto.alpha.contract.balance_too_low", events[1].Errors[0].ID)
+}
@@ -237,6 +237,7 @@ func (o *OperationError) UnmarshalJSON(data []byte) error {
 	}
 	o.Raw = make([]byte, len(data))
 	copy(o.Raw, data)
+	o.GenericError.raw = o.Raw
 	return nil
 }
 
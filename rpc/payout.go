@@ -0,0 +1,750 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// PayoutStatus is the payment state of a single delegator payout.
+type PayoutStatus byte
+
+const (
+	PayoutPending PayoutStatus = iota // planned, not yet sent
+	PayoutSkipped                     // not paid due to a payout rule
+	PayoutSent                        // broadcast, inclusion unknown
+	PayoutPaid                        // included in a block
+	PayoutFailed                      // send failed or included as failed
+)
+
+func (s PayoutStatus) String() string {
+	switch s {
+	case PayoutPending:
+		return "pending"
+	case PayoutSkipped:
+		return "skipped"
+	case PayoutSent:
+		return "sent"
+	case PayoutPaid:
+		return "paid"
+	case PayoutFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+func (s PayoutStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *PayoutStatus) UnmarshalText(data []byte) error {
+	switch string(data) {
+	case "pending":
+		*s = PayoutPending
+	case "skipped":
+		*s = PayoutSkipped
+	case "sent":
+		*s = PayoutSent
+	case "paid":
+		*s = PayoutPaid
+	case "failed":
+		*s = PayoutFailed
+	default:
+		return fmt.Errorf("invalid payout status %q", string(data))
+	}
+	return nil
+}
+
+// PayoutOverdelegation selects how rewards are shared when delegated balance
+// exceeds the baker's delegation capacity.
+type PayoutOverdelegation byte
+
+const (
+	// PayoutProRata shares rewards over the full delegated balance so that
+	// overdelegation dilutes every delegator.
+	PayoutProRata PayoutOverdelegation = iota
+	// PayoutCovered pays delegators as if the baker was not overdelegated.
+	// The baker covers the difference from their own share.
+	PayoutCovered
+)
+
+// PayoutConfig defines how delegator shares are computed. Fees are in
+// millionth, amounts in mutez.
+type PayoutConfig struct {
+	Fee            int64                   `json:"fee"`                     // baker fee on gross rewards
+	Fees           map[tezos.Address]int64 `json:"fees,omitempty"`          // per-delegator fee overrides
+	MinPayment     int64                   `json:"min_payment"`             // skip net payments below
+	MinBalance     int64                   `json:"min_balance"`             // skip snapshot balances below
+	Exclude        []tezos.Address         `json:"exclude,omitempty"`       // never paid, share stays with baker
+	Overdelegation PayoutOverdelegation    `json:"overdelegation"`          // overdelegation policy
+	IncludeFees    bool                    `json:"include_fees"`            // share block fees too
+	UseEstimate    bool                    `json:"use_estimate"`            // pay expected instead of actual rewards
+	TxFee          int64                   `json:"tx_fee,omitempty"`        // optional fixed fee per transfer
+	Retry          bool                    `json:"retry_failed"`            // resend failed payouts on Pay
+	Confirmations  int64                   `json:"confirmations,omitempty"` // wait depth before paid
+}
+
+func (c PayoutConfig) fee(addr tezos.Address) int64 {
+	if f, ok := c.Fees[addr]; ok {
+		return f
+	}
+	return c.Fee
+}
+
+func (c PayoutConfig) excluded(addr tezos.Address) bool {
+	for _, v := range c.Exclude {
+		if v.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// PayoutBalance is the balance of a delegator at the snapshot block.
+type PayoutBalance struct {
+	Address tezos.Address `json:"address"`
+	Balance int64         `json:"balance"`
+}
+
+// PayoutSnapshot is the delegation state that produced rights for a cycle.
+type PayoutSnapshot struct {
+	Baker        tezos.Address   `json:"baker"`
+	Cycle        int64           `json:"cycle"`
+	Height       int64           `json:"height"`        // snapshot block
+	BakerBalance int64           `json:"baker_balance"` // baker's own delegated balance
+	Capacity     int64           `json:"capacity"`      // max delegated balance, 0 is unlimited
+	Delegators   []PayoutBalance `json:"delegators"`
+}
+
+// Delegated returns the total delegated balance including the baker's.
+func (s *PayoutSnapshot) Delegated() int64 {
+	sum := s.BakerBalance
+	for _, v := range s.Delegators {
+		sum += v.Balance
+	}
+	return sum
+}
+
+// PayoutItem is the planned and actual payment to a single delegator.
+type PayoutItem struct {
+	Delegator tezos.Address   `json:"delegator"`
+	Balance   int64           `json:"balance"` // snapshot balance
+	Gross     int64           `json:"gross"`   // share before fees
+	Fee       int64           `json:"fee"`     // baker fee withheld
+	Amount    int64           `json:"amount"`  // net payment
+	Status    PayoutStatus    `json:"status"`
+	Reason    string          `json:"reason,omitempty"`
+	OpHash    tezos.OpHash    `json:"op_hash,omitempty"`
+	Block     tezos.BlockHash `json:"block,omitempty"`
+	Height    int64           `json:"height,omitempty"`
+	List      int             `json:"list,omitempty"`
+	Pos       int             `json:"pos,omitempty"`
+}
+
+// PayoutPlan lists all delegator payouts of a baker for one cycle. Plans are
+// persisted in a PayoutStore and updated while payments progress so that
+// interrupted runs can resume without paying anyone twice.
+type PayoutPlan struct {
+	Baker          tezos.Address `json:"baker"`
+	Cycle          int64         `json:"cycle"`
+	SnapshotHeight int64         `json:"snapshot_height"`
+	Rewards        int64         `json:"rewards"`   // rewards shared with delegators
+	Delegated      int64         `json:"delegated"` // total delegated balance incl. baker
+	Capacity       int64         `json:"capacity"`  // delegation capacity, 0 is unlimited
+	Items          []*PayoutItem `json:"items"`
+	Created        time.Time     `json:"created"`
+	Updated        time.Time     `json:"updated"`
+}
+
+// Amount returns the sum of net payments that are not skipped.
+func (p *PayoutPlan) Amount() int64 {
+	var sum int64
+	for _, v := range p.Items {
+		if v.Status != PayoutSkipped {
+			sum += v.Amount
+		}
+	}
+	return sum
+}
+
+// Filter returns items in status s.
+func (p *PayoutPlan) Filter(s PayoutStatus) []*PayoutItem {
+	var list []*PayoutItem
+	for _, v := range p.Items {
+		if v.Status == s {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// NewPayoutPlan computes delegator shares of rewards from snapshot s under
+// config c. The baker's own balance takes part in the split but the
+// baker's share is not listed.
+func NewPayoutPlan(s *PayoutSnapshot, rewards int64, c PayoutConfig) *PayoutPlan {
+	plan := &PayoutPlan{
+		Baker:          s.Baker,
+		Cycle:          s.Cycle,
+		SnapshotHeight: s.Height,
+		Rewards:        rewards,
+		Delegated:      s.Delegated(),
+		Capacity:       s.Capacity,
+		Items:          make([]*PayoutItem, 0, len(s.Delegators)),
+		Created:        time.Now().UTC(),
+	}
+	base := plan.Delegated
+	if c.Overdelegation == PayoutCovered && s.Capacity > 0 && base > s.Capacity {
+		base = s.Capacity
+	}
+	for _, v := range s.Delegators {
+		item := &PayoutItem{
+			Delegator: v.Address,
+			Balance:   v.Balance,
+		}
+		if base > 0 && rewards > 0 {
+			item.Gross = mulDiv(rewards, v.Balance, base)
+		}
+		item.Fee = mulDiv(item.Gross, c.fee(v.Address), 1_000_000)
+		item.Amount = item.Gross - item.Fee
+		switch {
+		case c.excluded(v.Address):
+			item.Status, item.Reason = PayoutSkipped, "excluded"
+		case v.Balance < c.MinBalance:
+			item.Status, item.Reason = PayoutSkipped, "balance below minimum"
+		case item.Amount <= 0 || item.Amount < c.MinPayment:
+			item.Status, item.Reason = PayoutSkipped, "payment below minimum"
+		}
+		plan.Items = append(plan.Items, item)
+	}
+	sort.SliceStable(plan.Items, func(i, j int) bool {
+		return plan.Items[i].Balance > plan.Items[j].Balance
+	})
+	plan.Updated = plan.Created
+	return plan
+}
+
+// PayoutStore persists payout plans.
+type PayoutStore interface {
+	// Load returns the plan for baker and cycle or nil when none exists.
+	Load(baker tezos.Address, cycle int64) (*PayoutPlan, error)
+	Save(p *PayoutPlan) error
+}
+
+// FilePayoutStore is a PayoutStore that keeps one JSON file per baker and
+// cycle in a directory. Files are replaced atomically on update.
+type FilePayoutStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewFilePayoutStore(dir string) (*FilePayoutStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FilePayoutStore{dir: dir}, nil
+}
+
+func (f *FilePayoutStore) path(baker tezos.Address, cycle int64) string {
+	return filepath.Join(f.dir, fmt.Sprintf("%s-%d.json", baker, cycle))
+}
+
+func (f *FilePayoutStore) Load(baker tezos.Address, cycle int64) (*PayoutPlan, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := os.ReadFile(f.path(baker, cycle))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := &PayoutPlan{}
+	if err := json.Unmarshal(buf, p); err != nil {
+		return nil, fmt.Errorf("payout: %s: %w", filepath.Base(f.path(baker, cycle)), err)
+	}
+	return p, nil
+}
+
+func (f *FilePayoutStore) Save(p *PayoutPlan) error {
+	buf, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(p.Baker, p.Cycle))
+}
+
+// PayoutIssue is a payout that needs attention after reconciliation.
+type PayoutIssue struct {
+	Item   *PayoutItem `json:"item"`
+	Reason string      `json:"reason"`
+}
+
+// PayoutReport reconciles a payout plan against the chain.
+type PayoutReport struct {
+	Baker   tezos.Address        `json:"baker"`
+	Cycle   int64                `json:"cycle"`
+	Rewards int64                `json:"rewards"` // rewards shared with delegators
+	Planned int64                `json:"planned"` // net payments not skipped
+	Paid    int64                `json:"paid"`    // net payments confirmed on chain
+	Fees    int64                `json:"fees"`    // baker fees on planned payments
+	Kept    int64                `json:"kept"`    // baker share, fees, skipped payouts and rounding
+	Counts  map[PayoutStatus]int `json:"counts"`
+	Issues  []PayoutIssue        `json:"issues,omitempty"`
+}
+
+// IsComplete returns true when all planned payouts are confirmed.
+func (r *PayoutReport) IsComplete() bool {
+	return len(r.Issues) == 0
+}
+
+// reconcile checks paid items against their included operations ops.
+func (p *PayoutPlan) reconcile(ops map[tezos.OpHash]*Operation) *PayoutReport {
+	r := &PayoutReport{
+		Baker:   p.Baker,
+		Cycle:   p.Cycle,
+		Rewards: p.Rewards,
+		Counts:  make(map[PayoutStatus]int),
+	}
+	issue := func(item *PayoutItem, reason string) {
+		r.Issues = append(r.Issues, PayoutIssue{Item: item, Reason: reason})
+	}
+	for _, item := range p.Items {
+		r.Counts[item.Status]++
+		if item.Status == PayoutSkipped {
+			continue
+		}
+		r.Planned += item.Amount
+		r.Fees += item.Fee
+		switch item.Status {
+		case PayoutPending:
+			issue(item, "not sent")
+		case PayoutSent:
+			if item.OpHash.IsValid() {
+				issue(item, "not confirmed")
+			} else {
+				issue(item, "send interrupted, check source history before retrying")
+			}
+		case PayoutFailed:
+			issue(item, "failed: "+item.Reason)
+		case PayoutPaid:
+			op, ok := ops[item.OpHash]
+			if !ok {
+				issue(item, "operation not found")
+				continue
+			}
+			if !payoutIncluded(op, item) {
+				issue(item, "no matching transfer in operation")
+				continue
+			}
+			r.Paid += item.Amount
+		}
+	}
+	r.Kept = r.Rewards - r.Planned
+	return r
+}
+
+// payoutIncluded returns true when op contains a successful transfer of the
+// item amount to its delegator.
+func payoutIncluded(op *Operation, item *PayoutItem) bool {
+	for _, v := range op.Contents {
+		tx, ok := v.(*Transaction)
+		if !ok || !tx.Destination.Equal(item.Delegator) || tx.Amount != item.Amount {
+			continue
+		}
+		if tx.Result().Status.IsSuccess() {
+			return true
+		}
+	}
+	return false
+}
+
+// PayoutSender splits and sends payout batches. It is implemented by
+// *Client.
+type PayoutSender interface {
+	SplitBatch(ctx context.Context, contents []codec.Operation, limits BatchLimits, opts *CallOptions) ([]*codec.Op, error)
+	SendAsync(ctx context.Context, op *codec.Op, opts *CallOptions) (*Result, error)
+}
+
+// PayoutEngine computes and pays delegator rewards per cycle.
+type PayoutEngine struct {
+	c *Client
+
+	Config  PayoutConfig
+	Store   PayoutStore
+	Sender  PayoutSender
+	Rewards *RewardEstimator
+	Options *CallOptions // signer and sender for payments
+	Workers int          // parallel balance fetchers
+}
+
+func NewPayoutEngine(c *Client, store PayoutStore) *PayoutEngine {
+	return &PayoutEngine{
+		c:       c,
+		Store:   store,
+		Sender:  c,
+		Rewards: NewRewardEstimator(c),
+		Workers: 4,
+	}
+}
+
+// payoutConstants are protocol constants not mapped into tezos.Params.
+type payoutConstants struct {
+	DelegationLimit int64 `json:"limit_of_delegation_over_baking"`
+}
+
+// Snapshot reads delegators of baker and their balances at the snapshot
+// block that produced rights for cycle.
+func (e *PayoutEngine) Snapshot(ctx context.Context, baker tezos.Address, cycle int64) (*PayoutSnapshot, error) {
+	p, err := e.c.GetParams(ctx, Head)
+	if err != nil {
+		return nil, err
+	}
+	head, err := e.c.GetBlockHeader(ctx, Head)
+	if err != nil {
+		return nil, err
+	}
+	var id BlockID = Head
+	if cycle <= p.CycleFromHeight(head.Level) {
+		id = BlockLevel(p.AtCycle(cycle).CycleStartHeight(cycle))
+	}
+	idx, err := e.c.GetSnapshotIndexCycle(ctx, id, cycle)
+	if err != nil {
+		return nil, err
+	}
+	snap := &PayoutSnapshot{
+		Baker:  baker,
+		Cycle:  cycle,
+		Height: p.AtCycle(idx.Base).SnapshotBlock(cycle, idx.Index),
+	}
+	at := BlockLevel(snap.Height)
+
+	d, err := e.c.GetDelegate(ctx, baker, at)
+	if err != nil {
+		return nil, err
+	}
+	var con payoutConstants
+	if err := e.c.GetCustomConstants(ctx, at, &con); err != nil {
+		return nil, err
+	}
+	staked := int64(d.OwnStaked)
+	if staked == 0 {
+		staked = d.FrozenDeposits
+	}
+	snap.Capacity = con.DelegationLimit * staked
+
+	list := d.Delegators
+	if len(list) == 0 {
+		list = d.DelegatedContracts
+	}
+	addrs := make([]tezos.Address, 0, len(list))
+	for _, v := range list {
+		if !v.Equal(baker) {
+			addrs = append(addrs, v)
+		}
+	}
+	if d.OwnDelegated > 0 {
+		snap.BakerBalance = int64(d.OwnDelegated)
+	} else {
+		addrs = append(addrs, baker)
+	}
+	bal, err := e.balances(ctx, at, addrs)
+	if err != nil {
+		return nil, err
+	}
+	for i, a := range addrs {
+		if a.Equal(baker) {
+			snap.BakerBalance = bal[i]
+			continue
+		}
+		snap.Delegators = append(snap.Delegators, PayoutBalance{Address: a, Balance: bal[i]})
+	}
+	return snap, nil
+}
+
+// balances fetches spendable balances of addrs at block id in parallel.
+func (e *PayoutEngine) balances(ctx context.Context, id BlockID, addrs []tezos.Address) ([]int64, error) {
+	var (
+		res    = make([]int64, len(addrs))
+		next   = make(chan int)
+		wg     sync.WaitGroup
+		once   sync.Once
+		failed error
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fail := func(err error) {
+		once.Do(func() {
+			failed = err
+			cancel()
+		})
+	}
+	workers := e.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range next {
+				bal, err := e.c.GetContractBalance(ctx, addrs[n], id)
+				if err != nil {
+					fail(fmt.Errorf("payout: balance %s: %w", addrs[n], err))
+					return
+				}
+				res[n] = bal.Int64()
+			}
+		}()
+	}
+	func() {
+		defer close(next)
+		for n := range addrs {
+			select {
+			case <-ctx.Done():
+				return
+			case next <- n:
+			}
+		}
+	}()
+	wg.Wait()
+
+	if failed != nil {
+		return nil, failed
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Distributable returns the rewards of baker in cycle that are shared with
+// delegators, i.e. rewards paid for delegated balance and optionally fees.
+func (e *PayoutEngine) Distributable(ctx context.Context, baker tezos.Address, cycle int64) (int64, error) {
+	var r RewardBreakdown
+	if e.Config.UseEstimate {
+		est, err := e.Rewards.Estimate(ctx, baker, cycle)
+		if err != nil {
+			return 0, err
+		}
+		r = est.Expected
+	} else {
+		act, err := e.Rewards.Actual(ctx, baker, cycle)
+		if err != nil {
+			return 0, err
+		}
+		r = act
+	}
+	amount := r.Delegated
+	if e.Config.IncludeFees {
+		amount += r.Fees
+	}
+	return amount, nil
+}
+
+// Prepare returns the stored plan for baker and cycle or creates and stores
+// a new plan from the cycle's snapshot and rewards.
+func (e *PayoutEngine) Prepare(ctx context.Context, baker tezos.Address, cycle int64) (*PayoutPlan, error) {
+	plan, err := e.Store.Load(baker, cycle)
+	if err != nil || plan != nil {
+		return plan, err
+	}
+	snap, err := e.Snapshot(ctx, baker, cycle)
+	if err != nil {
+		return nil, err
+	}
+	rewards, err := e.Distributable(ctx, baker, cycle)
+	if err != nil {
+		return nil, err
+	}
+	plan = NewPayoutPlan(snap, rewards, e.Config)
+	if err := e.Store.Save(plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Run prepares and pays the plan for baker and cycle. Running it again
+// after an interruption only sends payouts that were not sent before.
+func (e *PayoutEngine) Run(ctx context.Context, baker tezos.Address, cycle int64) (*PayoutPlan, error) {
+	plan, err := e.Prepare(ctx, baker, cycle)
+	if err != nil {
+		return nil, err
+	}
+	return plan, e.Pay(ctx, plan)
+}
+
+// Pay sends all pending payouts in plan as batched transfers sized by the
+// protocol limits. Items are marked sent and saved before broadcast so
+// that an interrupted send is never repeated automatically. Items are
+// only marked failed when sending stopped before broadcast or the node
+// refused the operation. When the broadcast outcome is unknown items stay
+// sent with their operation hash until Reconcile confirms them.
+func (e *PayoutEngine) Pay(ctx context.Context, plan *PayoutPlan) error {
+	opts := e.Options
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	var (
+		contents []codec.Operation
+		items    = make(map[codec.Operation]*PayoutItem)
+	)
+	for _, item := range plan.Items {
+		switch item.Status {
+		case PayoutPending:
+		case PayoutFailed:
+			if !e.Config.Retry {
+				continue
+			}
+		default:
+			continue
+		}
+		tx := &codec.Transaction{
+			Amount:      tezos.N(item.Amount),
+			Destination: item.Delegator,
+		}
+		if e.Config.TxFee > 0 {
+			tx.WithLimits(tezos.Limits{Fee: e.Config.TxFee})
+		}
+		contents = append(contents, tx)
+		items[tx] = item
+	}
+	if len(contents) == 0 {
+		return nil
+	}
+	groups, err := e.Sender.SplitBatch(ctx, contents, NewBatchLimits(e.c.Params), opts)
+	if err != nil {
+		return err
+	}
+	save := func() error {
+		plan.Updated = time.Now().UTC()
+		return e.Store.Save(plan)
+	}
+	for i, op := range groups {
+		group := make([]*PayoutItem, 0, len(op.Contents))
+		for _, v := range op.Contents {
+			if item, ok := items[v]; ok {
+				item.Status, item.Reason = PayoutSent, ""
+				item.OpHash = tezos.OpHash{}
+				group = append(group, item)
+			}
+		}
+		if err := save(); err != nil {
+			return err
+		}
+		res, err := e.Sender.SendAsync(ctx, op, opts)
+		if err != nil {
+			var be *BroadcastError
+			for _, item := range group {
+				switch {
+				case errors.As(err, &be) && !be.IsRefused():
+					// the operation may still be included
+					item.OpHash, item.Reason = be.Hash, err.Error()
+				default:
+					item.Status, item.Reason = PayoutFailed, err.Error()
+				}
+			}
+			if err2 := save(); err2 != nil {
+				return err2
+			}
+			return fmt.Errorf("payout group %d: %w", i, err)
+		}
+		for _, item := range group {
+			item.OpHash = res.Hash()
+		}
+		if err := save(); err != nil {
+			return err
+		}
+		if e.Config.Confirmations > 0 {
+			res.WithConfirmations(e.Config.Confirmations)
+		}
+		res.WaitContext(ctx)
+		if err := res.Err(); err != nil {
+			// keep the latest fee-bumped variant for reconciliation
+			for _, item := range group {
+				item.OpHash = res.Hash()
+			}
+			if err2 := save(); err2 != nil {
+				return err2
+			}
+			return fmt.Errorf("payout group %d: %w", i, err)
+		}
+		rcpt, err := res.GetReceipt(ctx)
+		if err != nil {
+			return fmt.Errorf("payout group %d: %w", i, err)
+		}
+		// a replacement may have been included instead of the original
+		hash := res.Hash()
+		if rcpt.Op != nil {
+			hash = rcpt.Op.Hash
+		}
+		for _, item := range group {
+			item.OpHash = hash
+			item.Block, item.Height = rcpt.Block, rcpt.Height
+			item.List, item.Pos = rcpt.List, rcpt.Pos
+			if payoutIncluded(rcpt.Op, item) {
+				item.Status = PayoutPaid
+			} else {
+				item.Status, item.Reason = PayoutFailed, "transfer failed"
+			}
+		}
+		if err := save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reconcile checks every paid item in plan against its included operation
+// and reports payouts that are missing, unconfirmed or failed.
+func (e *PayoutEngine) Reconcile(ctx context.Context, plan *PayoutPlan) (*PayoutReport, error) {
+	ops := make(map[tezos.OpHash]*Operation)
+	for _, item := range plan.Items {
+		if item.Status != PayoutPaid || item.Height == 0 {
+			continue
+		}
+		if _, ok := ops[item.OpHash]; ok {
+			continue
+		}
+		op, err := e.c.GetBlockOperation(ctx, BlockLevel(item.Height), item.List, item.Pos)
+		if err != nil {
+			return nil, err
+		}
+		if !op.Hash.Equal(item.OpHash) {
+			// reorged into another position or block
+			continue
+		}
+		ops[item.OpHash] = op
+	}
+	return plan.reconcile(ops), nil
+}
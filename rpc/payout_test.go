@@ -0,0 +1,270 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+const (
+	payoutBaker = "tz1S5WxdZR5f9NzsPXhr7L9L1vrEb5spZFur"
+	payoutUser  = "tz1aRoaRhSpRYvFdyvgWLL6TGyRoGF51wDjM"
+	payoutA     = "tz1WnfXMPaNTBmH7DBPwqCWs9cPDJdkGBTZ8"
+	payoutB     = "tz1TnEtqDV9mZyts2pfMy6Jw1BTPs4LMjL8M"
+	payoutC     = "tz1iAuFqKbnrK6p18di4CEdVCZgB7X5tS4hX"
+)
+
+func payoutTestSnapshot() *PayoutSnapshot {
+	return &PayoutSnapshot{
+		Baker:        tezos.MustParseAddress(payoutBaker),
+		Cycle:        7,
+		Height:       1000,
+		BakerBalance: 1_600_000,
+		Delegators: []PayoutBalance{
+			{tezos.MustParseAddress(payoutB), 1_000_000},
+			{tezos.MustParseAddress(payoutUser), 4_000_000},
+			{tezos.MustParseAddress(payoutC), 400_000},
+			{tezos.MustParseAddress(payoutA), 3_000_000},
+		},
+	}
+}
+
+func payoutTestConfig() PayoutConfig {
+	return PayoutConfig{
+		Fee:        50_000, // 5%
+		Fees:       map[tezos.Address]int64{tezos.MustParseAddress(payoutUser): 100_000},
+		Exclude:    []tezos.Address{tezos.MustParseAddress(payoutB)},
+		MinBalance: 500_000,
+		MinPayment: 100_000,
+	}
+}
+
+func TestPayoutPlan(t *testing.T) {
+	snap := payoutTestSnapshot()
+	plan := NewPayoutPlan(snap, 1_000_000, payoutTestConfig())
+	assert.Equal(t, int64(10_000_000), plan.Delegated)
+
+	type row struct {
+		Addr   string
+		Gross  int64
+		Fee    int64
+		Amount int64
+		Status PayoutStatus
+		Reason string
+	}
+	var rows []row
+	for _, v := range plan.Items {
+		rows = append(rows, row{v.Delegator.String(), v.Gross, v.Fee, v.Amount, v.Status, v.Reason})
+	}
+	assert.Equal(t, []row{
+		{payoutUser, 400_000, 40_000, 360_000, PayoutPending, ""},
+		{payoutA, 300_000, 15_000, 285_000, PayoutPending, ""},
+		{payoutB, 100_000, 5_000, 95_000, PayoutSkipped, "excluded"},
+		{payoutC, 40_000, 2_000, 38_000, PayoutSkipped, "balance below minimum"},
+	}, rows)
+	assert.Equal(t, int64(645_000), plan.Amount())
+	assert.Len(t, plan.Filter(PayoutPending), 2)
+
+	// minimum payment
+	cfg := payoutTestConfig()
+	cfg.MinPayment = 300_000
+	plan = NewPayoutPlan(snap, 1_000_000, cfg)
+	assert.Equal(t, PayoutSkipped, plan.Items[1].Status)
+	assert.Equal(t, "payment below minimum", plan.Items[1].Reason)
+
+	// overdelegation
+	snap.Capacity = 5_000_000
+	plan = NewPayoutPlan(snap, 1_000_000, payoutTestConfig())
+	assert.Equal(t, int64(400_000), plan.Items[0].Gross)
+	cfg = payoutTestConfig()
+	cfg.Overdelegation = PayoutCovered
+	plan = NewPayoutPlan(snap, 1_000_000, cfg)
+	assert.Equal(t, int64(800_000), plan.Items[0].Gross)
+	assert.Equal(t, int64(5_000_000), plan.Capacity)
+}
+
+func TestPayoutStore(t *testing.T) {
+	store, err := NewFilePayoutStore(t.TempDir())
+	require.NoError(t, err)
+	baker := tezos.MustParseAddress(payoutBaker)
+
+	plan, err := store.Load(baker, 7)
+	require.NoError(t, err)
+	assert.Nil(t, plan)
+
+	plan = NewPayoutPlan(payoutTestSnapshot(), 1_000_000, payoutTestConfig())
+	plan.Items[0].Status = PayoutSent
+	plan.Items[0].OpHash = tezos.MustParseOpHash("oo6JPEAy8VuMRGaFuMmLNFFGdJgiaKfnmT1CpHJfKP3Ye5ZahiP")
+	require.NoError(t, store.Save(plan))
+
+	loaded, err := store.Load(baker, 7)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, plan.Items, loaded.Items)
+	assert.True(t, plan.Created.Equal(loaded.Created))
+
+	// other cycles are separate
+	plan, err = store.Load(baker, 8)
+	require.NoError(t, err)
+	assert.Nil(t, plan)
+}
+
+func TestPayoutReconcile(t *testing.T) {
+	plan := NewPayoutPlan(payoutTestSnapshot(), 1_000_000, payoutTestConfig())
+	hash := tezos.MustParseOpHash("oo6JPEAy8VuMRGaFuMmLNFFGdJgiaKfnmT1CpHJfKP3Ye5ZahiP")
+	raw := `{"hash":"oo6JPEAy8VuMRGaFuMmLNFFGdJgiaKfnmT1CpHJfKP3Ye5ZahiP","contents":[
+		{"kind":"transaction","source":"` + payoutBaker + `","fee":"1000","counter":"1","gas_limit":"1000","storage_limit":"0",
+		"amount":"360000","destination":"` + payoutUser + `",
+		"metadata":{"balance_updates":[],"operation_result":{"status":"applied"}}}
+	]}`
+	op := &Operation{}
+	decodeTestJSON(t, raw, op)
+
+	user, other := plan.Items[0], plan.Items[1]
+	user.Status, user.OpHash, user.Height = PayoutPaid, hash, 1010
+	other.Status, other.OpHash = PayoutSent, hash
+
+	r := plan.reconcile(map[tezos.OpHash]*Operation{hash: op})
+	assert.Equal(t, int64(1_000_000), r.Rewards)
+	assert.Equal(t, int64(645_000), r.Planned)
+	assert.Equal(t, int64(360_000), r.Paid)
+	assert.Equal(t, int64(55_000), r.Fees)
+	assert.Equal(t, int64(355_000), r.Kept)
+	assert.Equal(t, 1, r.Counts[PayoutPaid])
+	assert.Equal(t, 2, r.Counts[PayoutSkipped])
+	require.Len(t, r.Issues, 1)
+	assert.Equal(t, other, r.Issues[0].Item)
+	assert.Equal(t, "not confirmed", r.Issues[0].Reason)
+	assert.False(t, r.IsComplete())
+
+	// amounts must match
+	user.Amount++
+	r = plan.reconcile(map[tezos.OpHash]*Operation{hash: op})
+	assert.Equal(t, int64(0), r.Paid)
+	require.Len(t, r.Issues, 2)
+	assert.Equal(t, "no matching transfer in operation", r.Issues[0].Reason)
+
+	// missing operations
+	r = plan.reconcile(nil)
+	assert.Equal(t, "operation not found", r.Issues[0].Reason)
+}
+
+// payoutTestStore records item states on every save.
+type payoutTestStore struct {
+	saves [][]PayoutStatus
+}
+
+func (s *payoutTestStore) Load(tezos.Address, int64) (*PayoutPlan, error) {
+	return nil, nil
+}
+
+func (s *payoutTestStore) Save(p *PayoutPlan) error {
+	list := make([]PayoutStatus, len(p.Items))
+	for i, v := range p.Items {
+		list[i] = v.Status
+	}
+	s.saves = append(s.saves, list)
+	return nil
+}
+
+// payoutTestSender sends every transfer in its own group and returns the
+// next result or error from send.
+type payoutTestSender struct {
+	sent []*codec.Op
+	send func(op *codec.Op) (*Result, error)
+}
+
+func (s *payoutTestSender) SplitBatch(_ context.Context, contents []codec.Operation, _ BatchLimits, _ *CallOptions) ([]*codec.Op, error) {
+	groups := make([]*codec.Op, 0, len(contents))
+	for _, v := range contents {
+		groups = append(groups, codec.NewOp().WithContents(v))
+	}
+	return groups, nil
+}
+
+func (s *payoutTestSender) SendAsync(_ context.Context, op *codec.Op, _ *CallOptions) (*Result, error) {
+	s.sent = append(s.sent, op)
+	return s.send(op)
+}
+
+func TestPayoutPay(t *testing.T) {
+	var (
+		orig = tezos.MustParseOpHash("oo6JPEAy8VuMRGaFuMmLNFFGdJgiaKfnmT1CpHJfKP3Ye5ZahiP")
+		repl = tezos.MustParseOpHash("oogC8ju9tMDqeB6RiAXdch3hnt8u3Pbf2ZXyyhAmJAhjQ4q1wUS")
+		blk  = tezos.MustParseBlockHash("BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2")
+	)
+	server := newJSONServer(t, map[string]string{
+		"/operations/3/0": `{"hash":"` + repl.String() + `","contents":[{"kind":"transaction","source":"` + payoutBaker + `",` +
+			`"fee":"1000","counter":"2","gas_limit":"1000","storage_limit":"0","amount":"360000","destination":"` + payoutUser + `",` +
+			`"metadata":{"balance_updates":[],"operation_result":{"status":"applied"}}}]}`,
+	})
+	defer server.Close()
+	cli, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+	obs := NewObserver()
+	obs.c = cli
+
+	store := &payoutTestStore{}
+	sender := &payoutTestSender{}
+	e := NewPayoutEngine(cli, store)
+	e.Sender = sender
+	e.Config = payoutTestConfig()
+	e.Config.Retry = true
+	ctx := context.Background()
+
+	// the first payout is fee-bumped and the replacement is included, the
+	// second broadcast times out and may still reach the network
+	plan := NewPayoutPlan(payoutTestSnapshot(), 1_000_000, e.Config)
+	user, other := plan.Items[0], plan.Items[1]
+	sender.send = func(op *codec.Op) (*Result, error) {
+		if len(sender.sent) == 2 {
+			return nil, &BroadcastError{Hash: orig, Err: context.DeadlineExceeded}
+		}
+		res := NewResult(orig)
+		res.Listen(obs)
+		res.replace(repl)
+		res.match(repl)(&BlockHeaderLogEntry{Hash: blk}, 1010, 3, 0, false)
+		return res, nil
+	}
+	err = e.Pay(ctx, plan)
+	require.Error(t, err)
+	assert.Equal(t, []PayoutStatus{PayoutSent, PayoutPending}, store.saves[0][:2])
+	assert.Equal(t, PayoutPaid, user.Status)
+	assert.Equal(t, repl, user.OpHash)
+	assert.Equal(t, int64(1010), user.Height)
+	assert.Equal(t, PayoutSent, other.Status)
+	assert.Equal(t, orig, other.OpHash)
+
+	// payouts with unknown outcome are never sent again
+	require.NoError(t, e.Pay(ctx, plan))
+	assert.Len(t, sender.sent, 2)
+	r := plan.reconcile(nil)
+	require.Len(t, r.Issues, 2)
+	assert.Equal(t, other, r.Issues[1].Item)
+	assert.Equal(t, "not confirmed", r.Issues[1].Reason)
+
+	// errors before broadcast and refusals fail payouts for retry
+	for _, sendErr := range []error{
+		errors.New("simulation failed"),
+		&BroadcastError{Hash: orig, Err: &rpcError{httpError: &httpError{}, errors: Errors{GenericError{ID: "failure"}}}},
+	} {
+		plan = NewPayoutPlan(payoutTestSnapshot(), 1_000_000, e.Config)
+		sender.sent = nil
+		sender.send = func(*codec.Op) (*Result, error) {
+			return nil, sendErr
+		}
+		require.ErrorIs(t, e.Pay(ctx, plan), sendErr)
+		assert.Equal(t, PayoutFailed, plan.Items[0].Status)
+		assert.Equal(t, PayoutPending, plan.Items[1].Status)
+		require.Error(t, e.Pay(ctx, plan))
+		assert.Len(t, sender.sent, 2)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/trilitech/tzgo/tezos"
+)
+
+const headPath = "chains/main/blocks/head"
+
+// Pin resolves the current head once and returns a read view of c pinned
+// to that block. See PinBlock.
+func (c *Client) Pin(ctx context.Context) (*Client, error) {
+	hash, err := c.GetBlockHash(ctx, Head)
+	if err != nil {
+		return nil, err
+	}
+	return c.PinBlock(hash), nil
+}
+
+// PinBlock returns a read view of c that routes all requests for the head
+// block (including head offsets) to block hash. Calls that default to Head
+// then read a consistent snapshot across multiple requests. Explicit block
+// ids are sent unchanged. The view shares the HTTP client, observers and
+// settings of c and is meant for reads only.
+func (c *Client) PinBlock(hash tezos.BlockHash) *Client {
+	pinned := *c
+	pinned.pinned = hash
+	return &pinned
+}
+
+// Pinned returns the block hash the client is pinned to, or an invalid hash
+// when the client follows head.
+func (c *Client) Pinned() tezos.BlockHash {
+	return c.pinned
+}
+
+// pinPath rewrites head block references in urlpath to the pinned block.
+func (c *Client) pinPath(urlpath string) string {
+	if !c.pinned.IsValid() {
+		return urlpath
+	}
+	path := strings.TrimPrefix(urlpath, "/")
+	if !strings.HasPrefix(path, headPath) {
+		return urlpath
+	}
+	rest := path[len(headPath):]
+	if rest != "" && !strings.ContainsAny(rest[:1], "/~?") {
+		return urlpath
+	}
+	return "chains/main/blocks/" + c.pinned.String() + rest
+}
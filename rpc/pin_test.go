@@ -0,0 +1,82 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func TestPinPath(t *testing.T) {
+	hash := tezos.NewBlockHash(append(make([]byte, 31), 1))
+	c := (&Client{}).PinBlock(hash)
+	pinned := "chains/main/blocks/" + hash.String()
+	for path, want := range map[string]string{
+		"chains/main/blocks/head":                     pinned,
+		"chains/main/blocks/head/header":              pinned + "/header",
+		"/chains/main/blocks/head/context/big_maps/1": pinned + "/context/big_maps/1",
+		"chains/main/blocks/head~2/hash":              pinned + "~2/hash",
+		"chains/main/blocks/head?metadata=always":     pinned + "?metadata=always",
+		"chains/main/blocks/100/header":               "chains/main/blocks/100/header",
+		"chains/main/blocks/headless":                 "chains/main/blocks/headless",
+		"monitor/heads/main":                          "monitor/heads/main",
+	} {
+		assert.Equal(t, want, c.pinPath(path), path)
+	}
+	assert.Equal(t, hash, c.Pinned())
+	assert.False(t, (&Client{}).Pinned().IsValid())
+}
+
+func TestPinnedClient(t *testing.T) {
+	hash := tezos.NewBlockHash(append(make([]byte, 31), 1))
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/chains/main/blocks/head/hash" {
+			json.NewEncoder(w).Encode(hash)
+			return
+		}
+		w.Write([]byte(`{"int":"1"}`))
+	}))
+	defer server.Close()
+	c, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	addr := tezos.MustParseAddress("KT1Puc9St8wdNoGtLiD2WXaHbWU7styaxYhD")
+	key := tezos.MustParseExprHash("expruPBWMccKybChcJmGF8oMo263Ri6HgbKbAJRS8j6GbmqZJPJfVG")
+	p, err := c.Pin(ctx)
+	require.NoError(t, err)
+	_, err = p.GetContractStorage(ctx, addr, Head)
+	require.NoError(t, err)
+	_, err = p.GetBigmapValue(ctx, 1, key, Head)
+	require.NoError(t, err)
+	_, err = p.GetBigmapValue(ctx, 1, key, BlockLevel(5))
+	require.NoError(t, err)
+	_, err = c.GetContractStorage(ctx, addr, Head)
+	require.NoError(t, err)
+
+	pinned := "/chains/main/blocks/" + hash.String()
+	assert.Equal(t, []string{
+		"/chains/main/blocks/head/hash",
+		pinned + "/context/contracts/" + addr.String() + "/storage",
+		pinned + "/context/big_maps/1/" + key.String(),
+		"/chains/main/blocks/5/context/big_maps/1/" + key.String(),
+		"/chains/main/blocks/head/context/contracts/" + addr.String() + "/storage",
+	}, paths)
+}
@@ -0,0 +1,504 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// Ensure Pool implements the RpcClient interface
+var _ RpcClient = (*Pool)(nil)
+
+var ErrNoEndpoint = errors.New("rpc: no healthy endpoint")
+
+// InjectMode defines where a Pool sends operation injections.
+type InjectMode byte
+
+const (
+	InjectPrimary InjectMode = iota // inject to the primary (first healthy) endpoint
+	InjectAll                       // inject to all healthy endpoints at once
+)
+
+// poolBaseURL is a placeholder base URL for requests that are routed
+// through the pool transport.
+const poolBaseURL = "http://tzgo-pool/"
+
+// Endpoint is a single RPC node managed by a Pool.
+type Endpoint struct {
+	URL *url.URL
+
+	apiKey string
+	client *Client // used for health checks
+
+	mu           sync.RWMutex
+	healthy      bool
+	checked      bool
+	chainId      tezos.ChainIdHash
+	level        int64
+	bootstrapped bool
+	latency      time.Duration
+	lastCheck    time.Time
+	lastErr      error
+}
+
+// EndpointStatus is a snapshot of an endpoint's health.
+type EndpointStatus struct {
+	URL          string            `json:"url"`
+	Healthy      bool              `json:"healthy"`
+	ChainId      tezos.ChainIdHash `json:"chain_id"`
+	Level        int64             `json:"level"`
+	Bootstrapped bool              `json:"bootstrapped"`
+	Latency      time.Duration     `json:"latency"`
+	LastCheck    time.Time         `json:"last_check"`
+	Error        string            `json:"error,omitempty"`
+}
+
+func (e *Endpoint) Status() EndpointStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	s := EndpointStatus{
+		URL:          e.URL.Redacted(),
+		Healthy:      e.healthy,
+		ChainId:      e.chainId,
+		Level:        e.level,
+		Bootstrapped: e.bootstrapped,
+		Latency:      e.latency,
+		LastCheck:    e.lastCheck,
+	}
+	if e.lastErr != nil {
+		s.Error = e.lastErr.Error()
+	}
+	return s
+}
+
+func (e *Endpoint) IsHealthy() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.healthy
+}
+
+// usable returns true when the endpoint is healthy or has not been
+// checked yet.
+func (e *Endpoint) usable() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.healthy || !e.checked
+}
+
+func (e *Endpoint) fail(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.healthy = false
+	e.checked = true
+	e.lastErr = err
+}
+
+// check probes reachability, chain id, bootstrap state and head level.
+func (e *Endpoint) check(ctx context.Context) {
+	start := time.Now()
+	var (
+		id     tezos.ChainIdHash
+		status Status
+		head   *BlockHeader
+		err    error
+	)
+	id, err = e.client.GetChainId(ctx)
+	if err == nil {
+		status, err = e.client.GetStatus(ctx)
+	}
+	if err == nil {
+		head, err = e.client.GetTipHeader(ctx)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastCheck = time.Now()
+	e.checked = true
+	e.lastErr = err
+	if err != nil {
+		e.healthy = false
+		return
+	}
+	e.latency = time.Since(start) / 3
+	e.chainId = id
+	e.bootstrapped = status.Bootstrapped
+	e.level = head.Level
+}
+
+// rpcClient allows Pool to embed Client without shadowing Client.Client().
+type rpcClient = Client
+
+// Pool is an RPC client that spreads requests over several Tezos nodes.
+// It continuously health-checks all endpoints, routes reads to the
+// healthiest node and transparently fails over to other nodes when a
+// request fails. Pool embeds a regular Client that uses the pool for
+// transport and implements RpcClient. Use Unwrap for APIs that require
+// a *Client such as contract bindings.
+type Pool struct {
+	*rpcClient
+
+	// InjectMode controls where operations are injected.
+	InjectMode InjectMode
+	// MaxLag is the max number of blocks a node may lag behind the
+	// best known head before it is considered unhealthy.
+	MaxLag int64
+	// CheckInterval is the time between health checks.
+	CheckInterval time.Duration
+
+	endpoints []*Endpoint
+	base      http.RoundTripper
+	once      sync.Once
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// NewPool creates a pool over RPC nodes at urls. The first url is the
+// primary node. Users may pass an optional http client with a custom
+// configuration, otherwise the http.DefaultClient is used.
+func NewPool(urls []string, httpClient *http.Client) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("rpc: empty endpoint list")
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		InjectMode:    InjectPrimary,
+		MaxLag:        2,
+		CheckInterval: 10 * time.Second,
+		base:          httpClient.Transport,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	if p.base == nil {
+		p.base = http.DefaultTransport
+	}
+	for _, v := range urls {
+		cli, err := NewClient(v, httpClient)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		u := *cli.BaseURL
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		p.endpoints = append(p.endpoints, &Endpoint{
+			URL:    &u,
+			apiKey: cli.ApiKey,
+			client: cli,
+		})
+	}
+	cli, err := NewClient(poolBaseURL, &http.Client{
+		Transport:     p,
+		Timeout:       httpClient.Timeout,
+		CheckRedirect: httpClient.CheckRedirect,
+		Jar:           httpClient.Jar,
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// endpoint-specific keys are set by the transport
+	if os.Getenv("TZGO_API_KEY") == cli.ApiKey {
+		cli.ApiKey = ""
+	}
+	p.rpcClient = cli
+	return p, nil
+}
+
+// Init checks all endpoints and loads chain config from the healthiest node.
+func (p *Pool) Init(ctx context.Context) error {
+	p.CheckHealth(ctx)
+	return p.rpcClient.Init(ctx)
+}
+
+// Listen starts background health checks and the client's observers.
+func (p *Pool) Listen() {
+	p.once.Do(func() {
+		go p.monitor()
+	})
+	p.rpcClient.Listen()
+}
+
+func (p *Pool) Close() {
+	p.cancel()
+	p.rpcClient.Close()
+}
+
+func (p *Pool) monitor() {
+	ticker := time.NewTicker(p.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.CheckHealth(p.ctx)
+		}
+	}
+}
+
+// Unwrap returns the pooled client.
+func (p *Pool) Unwrap() *Client {
+	return p.rpcClient
+}
+
+// Endpoints returns the current health status of all endpoints.
+func (p *Pool) Endpoints() []EndpointStatus {
+	list := make([]EndpointStatus, len(p.endpoints))
+	for i, e := range p.endpoints {
+		list[i] = e.Status()
+	}
+	return list
+}
+
+// CheckHealth probes all endpoints concurrently and updates their health
+// state. A node is healthy when it is reachable, bootstrapped, on the
+// expected chain and not lagging behind the best head by more than MaxLag.
+func (p *Pool) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range p.endpoints {
+		wg.Add(1)
+		go func(e *Endpoint) {
+			defer wg.Done()
+			e.check(ctx)
+		}(e)
+	}
+	wg.Wait()
+
+	// the expected chain is either configured or taken from the primary
+	// or first reachable node
+	chain := p.ChainId
+	var best int64
+	for _, e := range p.endpoints {
+		e.mu.RLock()
+		if e.lastErr == nil {
+			if !chain.IsValid() {
+				chain = e.chainId
+			}
+			if e.chainId.Equal(chain) && e.level > best {
+				best = e.level
+			}
+		}
+		e.mu.RUnlock()
+	}
+	for _, e := range p.endpoints {
+		e.mu.Lock()
+		switch {
+		case e.lastErr != nil:
+			e.healthy = false
+		case !e.chainId.Equal(chain):
+			e.healthy = false
+			e.lastErr = fmt.Errorf("rpc: chain id mismatch %s != %s", e.chainId, chain)
+		case !e.bootstrapped:
+			e.healthy = false
+			e.lastErr = fmt.Errorf("rpc: node not bootstrapped")
+		case best-e.level > p.MaxLag:
+			e.healthy = false
+			e.lastErr = fmt.Errorf("rpc: node lags %d blocks behind", best-e.level)
+		default:
+			e.healthy = true
+		}
+		e.mu.Unlock()
+	}
+}
+
+// candidates returns endpoints ordered by preference. Healthy nodes come
+// first sorted by head level and latency, unhealthy nodes are kept as
+// last resort.
+func (p *Pool) candidates() []*Endpoint {
+	type rank struct {
+		e       *Endpoint
+		healthy bool
+		level   int64
+		latency time.Duration
+	}
+	ranks := make([]rank, len(p.endpoints))
+	for i, e := range p.endpoints {
+		e.mu.RLock()
+		ranks[i] = rank{e, e.healthy || !e.checked, e.level, e.latency}
+		e.mu.RUnlock()
+	}
+	sort.SliceStable(ranks, func(i, j int) bool {
+		a, b := ranks[i], ranks[j]
+		if a.healthy != b.healthy {
+			return a.healthy
+		}
+		if a.level != b.level {
+			return a.level > b.level
+		}
+		return a.latency < b.latency
+	})
+	list := make([]*Endpoint, len(ranks))
+	for i, r := range ranks {
+		list[i] = r.e
+	}
+	return list
+}
+
+// healthy returns endpoints that are healthy or not checked yet.
+func (p *Pool) healthy() []*Endpoint {
+	list := make([]*Endpoint, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		if e.usable() {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// rewrite clones req for sending to endpoint e.
+func (p *Pool) rewrite(req *http.Request, e *Endpoint, body []byte) *http.Request {
+	r := req.Clone(req.Context())
+	r.URL = e.URL.ResolveReference(&url.URL{
+		Path:     strings.TrimPrefix(req.URL.Path, "/"),
+		RawQuery: req.URL.RawQuery,
+	})
+	r.Host = ""
+	if e.apiKey != "" {
+		r.Header.Set("X-Api-Key", e.apiKey)
+	}
+	if body != nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+	}
+	return r
+}
+
+// isUnavailable reports gateway and overload errors which indicate a node
+// (or its proxy) cannot serve requests. Other 5xx errors are regular RPC
+// errors that another node would return as well.
+func isUnavailable(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func isInjection(req *http.Request) bool {
+	return req.Method == http.MethodPost && strings.HasPrefix(strings.TrimPrefix(req.URL.Path, "/"), "injection/")
+}
+
+// RoundTrip implements http.RoundTripper and routes requests to pool endpoints.
+func (p *Pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if isInjection(req) {
+		return p.inject(req, body)
+	}
+	var (
+		resp    *http.Response
+		lastErr error = ErrNoEndpoint
+	)
+	idempotent := IsIdempotentRequest(req)
+	for _, e := range p.candidates() {
+		if req.Context().Err() != nil {
+			return nil, req.Context().Err()
+		}
+		r, err := p.base.RoundTrip(p.rewrite(req, e, body))
+		if err != nil {
+			e.fail(err)
+			lastErr = err
+			if !idempotent {
+				return nil, err
+			}
+			continue
+		}
+		if isUnavailable(r.StatusCode) && idempotent {
+			e.fail(fmt.Errorf("rpc: %s", r.Status))
+			if resp != nil {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			resp = r
+			continue
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		return r, nil
+	}
+	if resp != nil {
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// inject sends an operation to the primary or to all healthy nodes. The
+// request is never repeated on a node that may already have received it.
+func (p *Pool) inject(req *http.Request, body []byte) (*http.Response, error) {
+	targets := p.healthy()
+	if len(targets) == 0 {
+		return nil, ErrNoEndpoint
+	}
+	if p.InjectMode == InjectPrimary {
+		// prefer the configured primary node when healthy
+		if p.endpoints[0].usable() {
+			targets = p.endpoints[:1]
+		} else {
+			targets = p.candidates()[:1]
+		}
+	}
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	results := make(chan result, len(targets))
+	for _, e := range targets {
+		go func(e *Endpoint) {
+			r, err := p.base.RoundTrip(p.rewrite(req, e, body))
+			if err != nil {
+				e.fail(err)
+			}
+			results <- result{r, err}
+		}(e)
+	}
+	var (
+		best *http.Response
+		err  error
+	)
+	for range targets {
+		res := <-results
+		switch {
+		case res.err != nil:
+			err = res.err
+		case best == nil:
+			best = res.resp
+		case best.StatusCode/100 != 2 && res.resp.StatusCode/100 == 2:
+			io.Copy(io.Discard, best.Body)
+			best.Body.Close()
+			best = res.resp
+		default:
+			io.Copy(io.Discard, res.resp.Body)
+			res.resp.Body.Close()
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+	return nil, err
+}
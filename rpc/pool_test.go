@@ -0,0 +1,113 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const poolTestOpHash = "oogC8ju9tMDqeB6RiAXdch3hnt8u3Pbf2ZXyyhAmJAhjQ4q1wUS"
+
+type poolTestNode struct {
+	*httptest.Server
+	level    int64
+	chain    string
+	failing  atomic.Bool
+	injected atomic.Int32
+}
+
+func newPoolTestNode(t *testing.T, chain string, level int64) *poolTestNode {
+	n := &poolTestNode{chain: chain, level: level}
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chains/main/chain_id":
+			fmt.Fprintf(w, "%q", n.chain)
+		case "/chains/main/is_bootstrapped":
+			w.Write([]byte(`{"bootstrapped":true,"sync_state":"synced"}`))
+		case "/chains/main/blocks/head/header":
+			fmt.Fprintf(w, `{"level":%d}`, n.level)
+		case "/injection/operation":
+			n.injected.Add(1)
+			fmt.Fprintf(w, "%q", poolTestOpHash)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(n.Close)
+	return n
+}
+
+func TestPoolHealthAndFailover(t *testing.T) {
+	ctx := context.Background()
+	a := newPoolTestNode(t, "NetXdQprcVkpaWU", 100)
+	b := newPoolTestNode(t, "NetXdQprcVkpaWU", 105)
+	c := newPoolTestNode(t, "NetXnHfVqm9iesp", 200) // wrong chain
+
+	p, err := NewPool([]string{a.URL, b.URL, c.URL}, nil)
+	require.NoError(t, err)
+	defer p.Close()
+	p.CheckHealth(ctx)
+
+	s := p.Endpoints()
+	assert.False(t, s[0].Healthy, "lagging node")
+	assert.True(t, s[1].Healthy)
+	assert.False(t, s[2].Healthy, "wrong chain")
+
+	// reads go to the best node
+	head, err := p.GetTipHeader(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(105), head.Level)
+
+	// fail over when the best node breaks
+	a.level = 104
+	p.CheckHealth(ctx)
+	b.failing.Store(true)
+	head, err = p.GetTipHeader(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(104), head.Level)
+	assert.False(t, p.Endpoints()[1].Healthy)
+}
+
+func TestPoolInject(t *testing.T) {
+	ctx := context.Background()
+	a := newPoolTestNode(t, "NetXdQprcVkpaWU", 100)
+	b := newPoolTestNode(t, "NetXdQprcVkpaWU", 100)
+	p, err := NewPool([]string{a.URL, b.URL}, nil)
+	require.NoError(t, err)
+	defer p.Close()
+
+	// endpoints are usable before the first health check
+	hash, err := p.BroadcastOperation(ctx, []byte{0x1})
+	require.NoError(t, err)
+	assert.Equal(t, poolTestOpHash, hash.String())
+	assert.Equal(t, int32(1), a.injected.Load())
+	assert.Equal(t, int32(0), b.injected.Load())
+	p.CheckHealth(ctx)
+
+	p.InjectMode = InjectAll
+	_, err = p.BroadcastOperation(ctx, []byte{0x1})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), a.injected.Load())
+	assert.Equal(t, int32(1), b.injected.Load())
+
+	// injections are never retried on another node
+	a.failing.Store(true)
+	p.InjectMode = InjectPrimary
+	_, err = p.BroadcastOperation(ctx, []byte{0x1})
+	require.Error(t, err)
+	assert.Equal(t, int32(1), b.injected.Load())
+}
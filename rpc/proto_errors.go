@@ -0,0 +1,210 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/trilitech/tzgo/micheline"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// Protocol independent error codes of common failures.
+const (
+	CodeCounterInThePast      = "contract.counter_in_the_past"
+	CodeCounterInTheFuture    = "contract.counter_in_the_future"
+	CodeBalanceTooLow         = "contract.balance_too_low"
+	CodeGasExhausted          = "gas_exhausted.operation"
+	CodeGasExhaustedBlock     = "gas_exhausted.block"
+	CodeStorageExhausted      = "storage_exhausted.operation"
+	CodeScriptRejected        = "michelson_v1.script_rejected"
+	CodeUnrevealedKey         = "contract.unrevealed_key"
+	CodeEmptyImplicitContract = "implicit.empty_implicit_contract"
+	CodeEmptyDelegatedAccount = "implicit.empty_implicit_delegated_contract"
+	CodeFeesTooLow            = "prefilter.fees_too_low"
+)
+
+// Code returns the error id without protocol prefix, e.g.
+// contract.counter_in_the_past for proto.023-PtSeouLo.contract.counter_in_the_past.
+func (e GenericError) Code() string {
+	if strings.HasPrefix(e.ID, "proto.") {
+		if parts := strings.SplitN(e.ID, ".", 3); len(parts) == 3 {
+			return parts[2]
+		}
+	}
+	return e.ID
+}
+
+// As converts e into a typed protocol error when its code is known. It lets
+// errors.As find typed errors in RPC and receipt errors.
+func (e GenericError) As(target interface{}) bool {
+	typed := e.typed()
+	if typed == nil {
+		return false
+	}
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return false
+	}
+	if !reflect.TypeOf(typed).AssignableTo(val.Elem().Type()) {
+		return false
+	}
+	val.Elem().Set(reflect.ValueOf(typed))
+	return true
+}
+
+// typed returns the typed protocol error for e or nil.
+func (e GenericError) typed() error {
+	decode := func(v interface{}) {
+		if len(e.raw) > 0 {
+			_ = json.Unmarshal(e.raw, v)
+		}
+	}
+	switch e.Code() {
+	case CodeCounterInThePast, CodeCounterInTheFuture:
+		err := &CounterError{GenericError: e}
+		decode(err)
+		return err
+	case CodeBalanceTooLow:
+		err := &BalanceTooLowError{GenericError: e}
+		decode(err)
+		return err
+	case CodeGasExhausted, CodeGasExhaustedBlock:
+		return &GasExhaustedError{GenericError: e}
+	case CodeStorageExhausted:
+		return &StorageExhaustedError{GenericError: e}
+	case CodeScriptRejected:
+		err := &ScriptRejectedError{GenericError: e}
+		decode(err)
+		err.With = e.With
+		return err
+	case CodeUnrevealedKey:
+		err := &UnrevealedKeyError{GenericError: e}
+		decode(err)
+		return err
+	case CodeEmptyImplicitContract, CodeEmptyDelegatedAccount:
+		err := &EmptyImplicitContractError{GenericError: e}
+		decode(err)
+		return err
+	case CodeFeesTooLow:
+		return &FeesTooLowError{GenericError: e}
+	default:
+		return nil
+	}
+}
+
+// CounterError is returned when an operation counter does not match the
+// next expected account counter.
+type CounterError struct {
+	GenericError
+	Contract tezos.Address `json:"contract"`
+	Expected int64         `json:"expected,string"`
+	Found    int64         `json:"found,string"`
+}
+
+func (e *CounterError) Error() string {
+	return fmt.Sprintf("tezos: counter %d for %s is %s, expected %d", e.Found, e.Contract, e.direction(), e.Expected)
+}
+
+// InThePast returns true when the counter was already used.
+func (e *CounterError) InThePast() bool {
+	return e.Code() == CodeCounterInThePast
+}
+
+func (e *CounterError) direction() string {
+	if e.InThePast() {
+		return "in the past"
+	}
+	return "in the future"
+}
+
+// BalanceTooLowError is returned when a spendable balance does not cover
+// an amount.
+type BalanceTooLowError struct {
+	GenericError
+	Contract tezos.Address `json:"contract"`
+	Balance  int64         `json:"balance,string"`
+	Amount   int64         `json:"amount,string"`
+}
+
+func (e *BalanceTooLowError) Error() string {
+	return fmt.Sprintf("tezos: balance %d of %s too low for amount %d", e.Balance, e.Contract, e.Amount)
+}
+
+// GasExhaustedError is returned when an operation or block ran out of gas.
+type GasExhaustedError struct {
+	GenericError
+}
+
+func (e *GasExhaustedError) Error() string {
+	if e.Code() == CodeGasExhaustedBlock {
+		return "tezos: block gas exhausted"
+	}
+	return "tezos: operation gas exhausted"
+}
+
+// StorageExhaustedError is returned when an operation exceeds its storage limit.
+type StorageExhaustedError struct {
+	GenericError
+}
+
+func (e *StorageExhaustedError) Error() string {
+	return "tezos: operation storage exhausted"
+}
+
+// ScriptRejectedError is returned when a contract script fails with
+// FAILWITH. With holds the failure value and Location the position of the
+// failing instruction in the script.
+type ScriptRejectedError struct {
+	GenericError
+	Location int64 `json:"location"`
+}
+
+func (e *ScriptRejectedError) Error() string {
+	var reason string
+	if e.With.IsValid() {
+		reason = e.With.Dump()
+	}
+	return fmt.Sprintf("tezos: script rejected at location %d with %s", e.Location, reason)
+}
+
+// Value returns the decoded FAILWITH value.
+func (e *ScriptRejectedError) Value() micheline.Prim {
+	return e.With
+}
+
+// UnrevealedKeyError is returned when a manager operation is sent from an
+// account without revealed public key.
+type UnrevealedKeyError struct {
+	GenericError
+	Contract tezos.Address `json:"contract"`
+}
+
+func (e *UnrevealedKeyError) Error() string {
+	return fmt.Sprintf("tezos: key of %s is not revealed", e.Contract)
+}
+
+// EmptyImplicitContractError is returned when an operation is sent from an
+// implicit account that does not exist or has no balance.
+type EmptyImplicitContractError struct {
+	GenericError
+	Contract tezos.Address `json:"implicit"`
+}
+
+func (e *EmptyImplicitContractError) Error() string {
+	return fmt.Sprintf("tezos: implicit account %s is empty", e.Contract)
+}
+
+// FeesTooLowError is returned by the mempool when an operation pays less
+// than the minimal fee.
+type FeesTooLowError struct {
+	GenericError
+}
+
+func (e *FeesTooLowError) Error() string {
+	return "tezos: operation fees too low"
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/micheline"
+)
+
+func TestProtocolErrors(t *testing.T) {
+	addr := "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx"
+	var errs Errors
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"kind":"branch","id":"proto.023-PtSeouLo.contract.balance_too_low","contract":"`+addr+`","balance":"100","amount":"250"},
+		{"kind":"temporary","id":"proto.023-PtSeouLo.gas_exhausted.operation"},
+		{"kind":"temporary","id":"proto.alpha.storage_exhausted.operation"},
+		{"kind":"branch","id":"proto.023-PtSeouLo.contract.unrevealed_key","contract":"`+addr+`"},
+		{"kind":"temporary","id":"proto.023-PtSeouLo.implicit.empty_implicit_contract","implicit":"`+addr+`"},
+		{"kind":"permanent","id":"proto.023-PtSeouLo.prefilter.fees_too_low"},
+		{"kind":"permanent","id":"node.validator.checkpoint_error"}
+	]`), &errs))
+	require.Len(t, errs, 7)
+
+	var bal *BalanceTooLowError
+	require.True(t, errors.As(errs[0], &bal))
+	assert.Equal(t, addr, bal.Contract.String())
+	assert.Equal(t, int64(100), bal.Balance)
+	assert.Equal(t, int64(250), bal.Amount)
+	assert.Equal(t, "contract.balance_too_low", bal.Code())
+	assert.Equal(t, "branch", bal.ErrorKind())
+
+	var gas *GasExhaustedError
+	assert.True(t, errors.As(errs[1], &gas))
+	var store *StorageExhaustedError
+	assert.True(t, errors.As(errs[2], &store))
+	var unrevealed *UnrevealedKeyError
+	require.True(t, errors.As(errs[3], &unrevealed))
+	assert.Equal(t, addr, unrevealed.Contract.String())
+	var empty *EmptyImplicitContractError
+	require.True(t, errors.As(errs[4], &empty))
+	assert.Equal(t, addr, empty.Contract.String())
+	var fees *FeesTooLowError
+	assert.True(t, errors.As(errs[5], &fees))
+
+	// unknown errors and mismatching targets
+	assert.False(t, errors.As(errs[6], &fees))
+	assert.False(t, errors.As(errs[1], &fees))
+	assert.Equal(t, "node.validator.checkpoint_error", errs[6].(*GenericError).Code())
+
+	// lists match their first typed error
+	assert.True(t, errors.As(errs, &empty))
+	assert.True(t, errors.As(fmt.Errorf("send: %w", errs), &gas))
+}
+
+func TestCounterErrorFromRPC(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`[{"kind":"temporary","id":"failure","msg":"Error while applying operation"},
+			{"kind":"temporary","id":"proto.023-PtSeouLo.contract.counter_in_the_past",
+			"contract":"tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx","expected":"12","found":"10"}]`))
+	}))
+	defer server.Close()
+	c, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = c.BroadcastOperation(context.Background(), []byte{0})
+	require.Error(t, err)
+	var ce *CounterError
+	require.True(t, errors.As(err, &ce))
+	assert.True(t, ce.InThePast())
+	assert.Equal(t, int64(12), ce.Expected)
+	assert.Equal(t, int64(10), ce.Found)
+	assert.True(t, isCounterError(err))
+	assert.Equal(t, "failure", err.(RPCError).ErrorID())
+}
+
+func TestScriptRejected(t *testing.T) {
+	var op Operation
+	decodeTestJSON(t, `{
+		"hash": "oogC8ju9tMDqeB6RiAXdch3hnt8u3Pbf2ZXyyhAmJAhjQ4q1wUS",
+		"contents": [{
+			"kind":"transaction",
+			"source": "tz1KqTpEZ7Yob7QbPE4Hy4Wo8fHG8LhKxZSx",
+			"fee": "1000", "counter": "1", "gas_limit": "10000", "storage_limit": "0",
+			"amount": "0",
+			"destination": "KT1Puc9St8wdNoGtLiD2WXaHbWU7styaxYhD",
+			"metadata": {
+				"operation_result": {
+					"status": "failed",
+					"errors": [
+						{"kind":"temporary","id":"proto.023-PtSeouLo.michelson_v1.runtime_error","contract_handle":"KT1Puc9St8wdNoGtLiD2WXaHbWU7styaxYhD","contract_code":"Deprecated"},
+						{"kind":"temporary","id":"proto.023-PtSeouLo.michelson_v1.script_rejected","location":187,"with":{"prim":"Pair","args":[{"string":"NOT_OWNER"},{"int":"7"}]}}
+					]
+				}
+			}
+		}]
+	}`, &op)
+	rcpt := &Receipt{Op: &op}
+	err := rcpt.Error()
+	require.Error(t, err)
+
+	// receipts keep returning GenericError
+	_, ok := err.(GenericError)
+	assert.True(t, ok)
+
+	var sr *ScriptRejectedError
+	require.True(t, errors.As(err, &sr))
+	assert.Equal(t, int64(187), sr.Location)
+	assert.Equal(t, micheline.NewPair(micheline.NewString("NOT_OWNER"), micheline.NewInt64(7)).Dump(), sr.Value().Dump())
+	assert.Contains(t, sr.Error(), "location 187")
+}
+
+// decodeTestJSON decodes a node JSON fixture into v. Operation lists only
+// decode from compact JSON, so fixtures are compacted first.
+func decodeTestJSON(t *testing.T, raw string, v interface{}) {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, json.Compact(&buf, []byte(raw)))
+	require.NoError(t, json.Unmarshal(buf.Bytes(), v))
+}
@@ -58,7 +58,8 @@ func (r *Receipt) IsSuccess() bool {
 
 // Error returns the first execution error found in this operation group or one of
 // its internal results that is of status failed. This helper only exports the error
-// as GenericError. To access error details or all errors, visit
+// as GenericError. Use errors.As to access typed protocol errors like
+// *ScriptRejectedError. To access error details or all errors, visit
 // r.Op.Contents[].OperationResult.Errors[] and
 // r.Op.Contents[].Metadata.InternalResults.Result.Errors[]
 func (r *Receipt) Error() error {
@@ -105,50 +106,73 @@ func (r *Receipt) MinLimits() []tezos.Limits {
 	return lims
 }
 
+// Result tracks the lifecycle of a broadcast operation from mempool to
+// inclusion, confirmation and finality.
 type Result struct {
-	oh     tezos.OpHash    // the operation hash to watch
-	block  tezos.BlockHash // the block hash where op was included
-	height int64           // block height
-	list   int             // the list where op was included
-	pos    int             // the list position where op was included
-	err    error           // saves any error
-	ttl    int64           // number of blocks before wait fails
-	wait   int64           // number of confirmations required
-	blocks int64           // number of confirmation blocks seen
-	obs    *Observer       // blockchain observer
-	subId  int             // monitor subscription id
-	done   chan struct{}   // channel used to signal completion
-	once   sync.Once       // ensures only one completion state exists
+	ttl       int64         // number of blocks before wait fails
+	wait      int64         // number of confirmations required
+	obs       *Observer     // blockchain observer
+	mobs      *Observer     // mempool observer
+	subId     int           // monitor subscription id
+	ttlId     int           // ttl monitor subscription id
+	mempoolId int           // mempool monitor subscription id
+	extraIds  [2][]int      // block and mempool subscriptions for replacements
+	done      chan struct{} // channel used to signal completion
+	once      sync.Once     // ensures only one completion state exists
+
+	mu       sync.Mutex          // protects inclusion and lifecycle state below
+	oh       tezos.OpHash        // the operation hash to watch
+	block    tezos.BlockHash     // the block hash where op was included
+	height   int64               // block height
+	list     int                 // the list where op was included
+	pos      int                 // the list position where op was included
+	err      error               // saves any error
+	blocks   int64               // number of confirmation blocks seen
+	start    int64               // first block height seen while waiting
+	final    bool                // inclusion block is final
+	state    ResultState         // current lifecycle state
+	history  []ResultEvent       // all lifecycle events
+	handlers []func(ResultEvent) // event callbacks
+	notify   chan struct{}       // closed and replaced on every event
+	closed   bool                // lifecycle has ended
 }
 
 func NewResult(oh tezos.OpHash) *Result {
 	return &Result{
-		oh:   oh,
-		wait: 1,
-		done: make(chan struct{}),
+		oh:     oh,
+		wait:   1,
+		done:   make(chan struct{}),
+		notify: make(chan struct{}),
 	}
 }
 
+// Hash returns the operation hash. After a fee bump this is the hash of the
+// latest replacement or of the variant that was included.
 func (r *Result) Hash() tezos.OpHash {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.oh
 }
 
+// Listen tracks inclusion, confirmations and finality on block observer o.
 func (r *Result) Listen(o *Observer) {
 	if o != nil {
 		r.obs = o
-		r.subId = r.obs.Subscribe(r.oh, r.callback)
+		oh := r.Hash()
+		id := r.obs.SubscribeReorg(oh, r.match(oh), r.reorg)
+		var ttlId int
+		if r.ttl > 0 {
+			ttlId = r.obs.Subscribe(tezos.ZeroOpHash, r.ttlCallback)
+		}
+		r.mu.Lock()
+		r.subId, r.ttlId = id, ttlId
+		r.mu.Unlock()
 	}
 }
 
 func (r *Result) Cancel() {
-	r.once.Do(func() {
-		if r.subId > 0 {
-			r.obs.Unsubscribe(r.subId)
-			r.err = ErrCanceled
-			r.subId = 0
-		}
-		close(r.done)
-	})
+	r.complete(ErrCanceled)
+	r.finish()
 }
 
 func (r *Result) WithConfirmations(n int64) *Result {
@@ -162,6 +186,8 @@ func (r *Result) WithTTL(n int64) *Result {
 }
 
 func (r *Result) Confirmations() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.blocks
 }
 
@@ -170,21 +196,26 @@ func (r *Result) Done() <-chan struct{} {
 }
 
 func (r *Result) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.err
 }
 
 func (r *Result) GetReceipt(ctx context.Context) (*Receipt, error) {
-	if r.err != nil {
-		return nil, r.err
-	}
+	r.mu.Lock()
+	err := r.err
 	rec := &Receipt{
 		Block:  r.block,
 		Height: r.height,
 		Pos:    r.pos,
 		List:   r.list,
 	}
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
 	if r.obs != nil {
-		op, err := r.obs.c.GetBlockOperation(ctx, r.block, r.list, r.pos)
+		op, err := r.obs.c.GetBlockOperation(ctx, rec.Block, rec.List, rec.Pos)
 		if err != nil {
 			return rec, err
 		}
@@ -200,7 +231,9 @@ func (r *Result) Wait() {
 func (r *Result) WaitContext(ctx context.Context) bool {
 	select {
 	case <-ctx.Done():
+		r.mu.Lock()
 		r.err = context.Canceled
+		r.mu.Unlock()
 		return false
 	case <-r.done:
 		return true
@@ -208,34 +241,56 @@ func (r *Result) WaitContext(ctx context.Context) bool {
 }
 
 func (r *Result) callback(block *BlockHeaderLogEntry, height int64, list, pos int, force bool) bool {
+	r.mu.Lock()
 	if force {
-		r.block = block.Hash
-		r.height = height
-		r.list = list
-		r.pos = pos
+		r.block, r.height, r.list, r.pos = block.Hash, height, list, pos
+		r.mu.Unlock()
 		return false
 	}
-	if !r.block.IsValid() {
-		r.block = block.Hash
-		r.height = height
-		r.list = list
-		r.pos = pos
-	}
-	r.blocks++
-	if r.ttl > 0 && r.blocks >= r.ttl {
-		r.once.Do(func() {
-			r.err = ErrTTLExceeded
-			r.subId = 0
-			close(r.done)
-		})
-		return true
+	included := !r.block.IsValid()
+	if included {
+		r.block, r.height, r.list, r.pos = block.Hash, height, list, pos
 	}
-	if r.blocks >= r.wait {
-		r.once.Do(func() {
-			r.subId = 0
-			close(r.done)
-		})
+	// count confirmations on the canonical chain
+	r.blocks = height - r.height + 1
+	hash, start, blocks := r.block, r.height, r.blocks
+	final := blocks > FinalityDepth && !r.final
+	if final {
+		// keep watching for reorgs until the inclusion block is final
+		r.final = true
+	}
+	done := r.final && blocks >= r.wait
+	r.mu.Unlock()
+
+	if included {
+		r.emit(ResultEvent{State: ResultIncluded, Block: hash, Height: height, Confirmations: 1})
+	}
+	if blocks > 1 {
+		r.emit(ResultEvent{State: ResultConfirmed, Block: hash, Height: start, Confirmations: blocks})
+	}
+	if blocks >= r.wait {
+		r.complete(nil)
+	}
+	if final {
+		r.emit(ResultEvent{State: ResultFinal, Block: hash, Height: start, Confirmations: blocks})
+	}
+	if done {
+		r.finish()
 		return true
 	}
 	return false
 }
+
+// reorg resets the inclusion state after the inclusion block was orphaned.
+// The operation is matched again when included on the new branch.
+func (r *Result) reorg(orphan *BlockHeaderLogEntry) bool {
+	r.emit(ResultEvent{State: ResultReorged, Block: orphan.Hash, Height: orphan.Level})
+	r.mu.Lock()
+	r.block = tezos.ZeroBlockHash
+	r.height = 0
+	r.list = 0
+	r.pos = 0
+	r.blocks = 0
+	r.mu.Unlock()
+	return false
+}
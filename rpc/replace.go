@@ -0,0 +1,188 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"reflect"
+
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/signer"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// MinReplaceFeeFactor is the minimum fee increase the Octez mempool requires
+// to replace a manager operation with the same source and counter.
+const MinReplaceFeeFactor = 1.05
+
+// ReplacePolicy controls fee bumping for operations stuck in the mempool.
+// An operation is stuck when it is still waiting in the mempool (or was not
+// classified yet) After blocks since it was last broadcast. Stuck operations
+// are re-signed with the same counter and a higher fee and injected again.
+type ReplacePolicy struct {
+	After       int64   // blocks without inclusion before replacing
+	MaxAttempts int     // max number of replacements
+	FeeFactor   float64 // fee multiplier per replacement
+	MaxFee      int64   // max total fee of a replacement, optional
+
+	// Bump is an optional custom strategy that returns the next fee for an
+	// operation content. Results below the node's replacement threshold
+	// are raised to the minimum.
+	Bump func(fee int64, attempt int) int64
+}
+
+var DefaultReplacePolicy = ReplacePolicy{
+	After:       3,
+	MaxAttempts: 3,
+	FeeFactor:   1.25,
+}
+
+func NewReplacePolicy() *ReplacePolicy {
+	p := DefaultReplacePolicy
+	return &p
+}
+
+// NextFee returns the bumped fee for replacement number attempt (starting at 1).
+func (p *ReplacePolicy) NextFee(fee int64, attempt int) int64 {
+	var next int64
+	if p.Bump != nil {
+		next = p.Bump(fee, attempt)
+	} else {
+		next = int64(math.Ceil(float64(fee) * p.FeeFactor))
+	}
+	if min := int64(math.Ceil(float64(fee) * MinReplaceFeeFactor)); next < min {
+		next = min
+	}
+	if next <= fee {
+		next = fee + 1
+	}
+	return next
+}
+
+// BumpOp raises fees on all manager operations in op. It returns false when
+// the total fee would exceed MaxFee.
+func (p *ReplacePolicy) BumpOp(op *codec.Op, attempt int) bool {
+	var total int64
+	limits := make([]tezos.Limits, len(op.Contents))
+	for i, v := range op.Contents {
+		limits[i] = v.Limits()
+		if v.GetCounter() < 0 {
+			continue
+		}
+		limits[i].Fee = p.NextFee(limits[i].Fee, attempt)
+		total += limits[i].Fee
+	}
+	if p.MaxFee > 0 && total > p.MaxFee {
+		return false
+	}
+	for i, v := range op.Contents {
+		if v.GetCounter() >= 0 {
+			v.WithLimits(limits[i])
+		}
+	}
+	return true
+}
+
+// isStuck returns true when the operation is waiting for inclusion.
+func (r *Result) isStuck() bool {
+	switch r.State() {
+	case ResultBroadcast, ResultMempool, ResultReplaced, ResultReorged:
+		return true
+	default:
+		return false
+	}
+}
+
+// replaceStuck re-signs and re-broadcasts a copy of op with bumped fees while
+// res is stuck in the mempool. Failed broadcasts are emitted on res as
+// ResultReplaceFailed and end replacement. Replacements reuse the original counters, which stay
+// reserved until res completes, and are journaled under the original entry
+// before broadcast. It runs until the result completes, the policy is
+// exhausted or ctx is canceled.
+func (c *Client) replaceStuck(ctx context.Context, res *Result, op *codec.Op, s signer.Signer, addr tezos.Address, p *ReplacePolicy, mon *Observer, entry *JournalEntry) {
+	heads := make(chan struct{}, 1)
+	id := mon.Subscribe(tezos.ZeroOpHash, func(_ *BlockHeaderLogEntry, _ int64, _, _ int, _ bool) bool {
+		select {
+		case heads <- struct{}{}:
+		default:
+		}
+		return false
+	})
+	defer mon.Unsubscribe(id)
+
+	op, err := copyOp(op)
+	if err != nil {
+		c.Log.Warnf("replace: %v", err)
+		return
+	}
+	var blocks int64
+	for attempt := 1; attempt <= p.MaxAttempts; {
+		select {
+		case <-ctx.Done():
+			return
+		case <-res.Done():
+			return
+		case <-heads:
+		}
+		if !res.isStuck() {
+			// included or refused, keep watching for reorgs
+			blocks = 0
+			continue
+		}
+		if blocks++; blocks < p.After {
+			continue
+		}
+		if !p.BumpOp(op, attempt) {
+			c.Log.Debugf("replace: max fee reached for %s", res.Hash())
+			return
+		}
+		sig, err := s.SignOperation(ctx, addr, op)
+		if err != nil {
+			c.Log.Warnf("replace: sign failed: %v", err)
+			return
+		}
+		op.WithSignature(sig)
+		if entry != nil {
+			if err := c.Journal.replace(entry, op.Hash()); err != nil {
+				c.Log.Warnf("replace: %v", err)
+				return
+			}
+		}
+		hash, err := c.Broadcast(ctx, op)
+		if err != nil {
+			c.Log.Warnf("replace: broadcast failed: %v", err)
+			res.emit(ResultEvent{State: ResultReplaceFailed, Err: &BroadcastError{Hash: op.Hash(), Err: err}})
+			return
+		}
+		c.Log.Debugf("replace: %s replaced by %s with fee %d", res.Hash(), hash, op.Limits().Fee)
+		res.replace(hash)
+		blocks = 0
+		attempt++
+	}
+}
+
+// copyOp returns a deep copy of op so that fees and signature can change
+// while the caller still uses op.
+func copyOp(op *codec.Op) (*codec.Op, error) {
+	p := op.Params
+	if p == nil {
+		p = tezos.DefaultParams
+	}
+	cp := *op
+	cp.Contents = make([]codec.Operation, len(op.Contents))
+	for i, v := range op.Contents {
+		buf := bytes.NewBuffer(nil)
+		if err := v.EncodeBuffer(buf, p); err != nil {
+			return nil, err
+		}
+		o := reflect.New(reflect.TypeOf(v).Elem()).Interface().(codec.Operation)
+		if err := o.DecodeBuffer(buf, p); err != nil {
+			return nil, err
+		}
+		cp.Contents[i] = o
+	}
+	return &cp, nil
+}
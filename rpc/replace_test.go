@@ -0,0 +1,147 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/signer"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func TestReplacePolicyFees(t *testing.T) {
+	p := NewReplacePolicy()
+	assert.Equal(t, int64(1250), p.NextFee(1000, 1))
+	p.FeeFactor = 1.01
+	assert.Equal(t, int64(1050), p.NextFee(1000, 1), "node minimum")
+	assert.Equal(t, int64(1), p.NextFee(0, 1))
+	p.Bump = func(fee int64, attempt int) int64 { return fee + int64(attempt)*100 }
+	assert.Equal(t, int64(1200), p.NextFee(1000, 2))
+
+	op := codec.NewOp().WithTransfer(tezos.BurnAddress, 1)
+	op.Contents[0].WithLimits(tezos.Limits{Fee: 1000, GasLimit: 2000})
+	p.MaxFee = 1100
+	assert.True(t, p.BumpOp(op, 1))
+	assert.Equal(t, int64(1100), op.Limits().Fee)
+	assert.Equal(t, int64(2000), op.Limits().GasLimit)
+	assert.False(t, p.BumpOp(op, 1))
+	assert.Equal(t, int64(1100), op.Limits().Fee)
+}
+
+func TestReplaceStuck(t *testing.T) {
+	chain, cli := newTestChain(t)
+	key, err := tezos.GenerateKey(tezos.KeyTypeEd25519)
+	require.NoError(t, err)
+	addr := key.Address()
+
+	a := chain.add(nil, 1)
+	obs := NewObserver().WithDelay(5 * time.Millisecond)
+	obs.Listen(cli)
+	defer obs.Close()
+	require.Eventually(t, func() bool { return obs.isKnownLocked(a) }, time.Second, 5*time.Millisecond)
+
+	op := codec.NewOp().WithSource(addr).WithBranch(a.hash).WithTransfer(tezos.BurnAddress, 1)
+	op.Contents[0].WithLimits(tezos.Limits{Fee: 1000, GasLimit: 2000})
+	op.Contents[0].WithCounter(1)
+	s := signer.NewFromKey(key)
+	sig, err := s.SignOperation(context.Background(), addr, op)
+	require.NoError(t, err)
+	op.WithSignature(sig)
+	orig := op.Hash()
+
+	store, err := NewFileJournal(t.TempDir())
+	require.NoError(t, err)
+	cli.WithJournal(store)
+	entry, err := cli.Journal.record(op)
+	require.NoError(t, err)
+
+	res := NewResult(orig).WithConfirmations(1)
+	res.emit(ResultEvent{State: ResultBroadcast})
+	res.Listen(obs)
+	cli.Journal.track(entry, res)
+	p := &ReplacePolicy{After: 2, MaxAttempts: 1, FeeFactor: 2}
+	go cli.replaceStuck(context.Background(), res, op, s, addr, p, obs, entry)
+
+	// stuck for two blocks
+	for i := byte(2); i < 4; i++ {
+		a = chain.add(a, i)
+		require.Eventually(t, func() bool { return obs.isKnownLocked(a) }, time.Second, 5*time.Millisecond)
+	}
+	require.Eventually(t, func() bool { return res.State() == ResultReplaced }, time.Second, 5*time.Millisecond)
+	replaced := res.Hash()
+	assert.NotEqual(t, orig, replaced)
+	// the caller's op is left unchanged
+	assert.Equal(t, int64(1000), op.Limits().Fee)
+	assert.Equal(t, orig, op.Hash())
+
+	// the replacement lands
+	chain.add(a, 4, replaced)
+	res.Wait()
+	require.NoError(t, res.Err())
+	assert.Equal(t, replaced, res.Hash())
+	chain.Lock()
+	assert.Equal(t, []tezos.OpHash{replaced}, chain.injected)
+	chain.Unlock()
+
+	// the replacement is journaled once under the original entry
+	list, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, orig, list[0].Hash)
+	assert.Equal(t, []tezos.OpHash{replaced}, list[0].Replaced)
+}
+
+func TestReplaceStuckRefused(t *testing.T) {
+	chain, cli := newTestChain(t)
+	key, err := tezos.GenerateKey(tezos.KeyTypeEd25519)
+	require.NoError(t, err)
+	addr := key.Address()
+
+	a := chain.add(nil, 1)
+	obs := NewObserver().WithDelay(5 * time.Millisecond)
+	obs.Listen(cli)
+	defer obs.Close()
+	require.Eventually(t, func() bool { return obs.isKnownLocked(a) }, time.Second, 5*time.Millisecond)
+
+	op := codec.NewOp().WithSource(addr).WithBranch(a.hash).WithTransfer(tezos.BurnAddress, 1)
+	op.Contents[0].WithLimits(tezos.Limits{Fee: 1000, GasLimit: 2000})
+	op.Contents[0].WithCounter(1)
+	s := signer.NewFromKey(key)
+	sig, err := s.SignOperation(context.Background(), addr, op)
+	require.NoError(t, err)
+	op.WithSignature(sig)
+
+	chain.Lock()
+	chain.refuse = true
+	chain.Unlock()
+	res := NewResult(op.Hash()).WithConfirmations(1)
+	res.emit(ResultEvent{State: ResultBroadcast})
+	res.Listen(obs)
+	defer res.finish()
+	failed := make(chan error, 1)
+	res.OnEvent(func(ev ResultEvent) {
+		if ev.State == ResultReplaceFailed {
+			failed <- ev.Err
+		}
+	})
+	p := &ReplacePolicy{After: 1, MaxAttempts: 1, FeeFactor: 2}
+	go cli.replaceStuck(context.Background(), res, op, s, addr, p, obs, nil)
+
+	chain.add(a, 2)
+	select {
+	case err = <-failed:
+	case <-time.After(time.Second):
+		t.Fatal("replacement failure not emitted")
+	}
+	var berr *BroadcastError
+	require.True(t, errors.As(err, &berr))
+	assert.True(t, berr.IsRefused())
+	assert.Equal(t, op.Hash(), res.Hash())
+}
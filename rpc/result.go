@@ -0,0 +1,322 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// FinalityDepth is the number of blocks on top of a block after which the
+// block is final under Tenderbake.
+const FinalityDepth = 2
+
+var ErrRefused = errors.New("operation refused")
+
+// refusedError is ErrRefused with the first mempool error. It lets
+// errors.As find typed protocol errors of refused operations.
+type refusedError struct {
+	err GenericError
+}
+
+func (e refusedError) Error() string {
+	return ErrRefused.Error() + ": " + e.err.ID
+}
+
+func (e refusedError) Is(target error) bool {
+	return target == ErrRefused
+}
+
+func (e refusedError) Unwrap() error {
+	return e.err
+}
+
+// ResultState is a lifecycle state of an operation tracked by a Result.
+type ResultState byte
+
+const (
+	ResultBuilt         ResultState = iota // signed and ready for broadcast
+	ResultBroadcast                        // accepted by the node for injection
+	ResultMempool                          // validated or delayed in the mempool
+	ResultRefused                          // refused by the mempool
+	ResultIncluded                         // included in a block
+	ResultConfirmed                        // confirmed by n blocks
+	ResultFinal                            // included in a final block
+	ResultReorged                          // inclusion block was orphaned
+	ResultExpired                          // not included before TTL expired
+	ResultReplaced                         // re-broadcast with a higher fee
+	ResultReplaceFailed                    // fee-bumped replacement not broadcast
+)
+
+func (s ResultState) String() string {
+	switch s {
+	case ResultBuilt:
+		return "built"
+	case ResultBroadcast:
+		return "broadcast"
+	case ResultMempool:
+		return "mempool"
+	case ResultRefused:
+		return "refused"
+	case ResultIncluded:
+		return "included"
+	case ResultConfirmed:
+		return "confirmed"
+	case ResultFinal:
+		return "final"
+	case ResultReorged:
+		return "reorged"
+	case ResultExpired:
+		return "expired"
+	case ResultReplaced:
+		return "replaced"
+	case ResultReplaceFailed:
+		return "replace_failed"
+	default:
+		return "unknown"
+	}
+}
+
+func (s ResultState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ResultEvent is a timestamped lifecycle state change of an operation.
+type ResultEvent struct {
+	State         ResultState      `json:"state"`
+	Hash          tezos.OpHash     `json:"hash"`
+	Block         tezos.BlockHash  `json:"block"`
+	Height        int64            `json:"height,omitempty"`
+	Confirmations int64            `json:"confirmations,omitempty"`
+	Errors        []OperationError `json:"errors,omitempty"`
+	Err           error            `json:"-"` // broadcast error of a failed replacement
+	Time          time.Time        `json:"time"`
+}
+
+// State returns the most recent lifecycle state.
+func (r *Result) State() ResultState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state
+}
+
+// History returns all lifecycle events seen so far.
+func (r *Result) History() []ResultEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ResultEvent(nil), r.history...)
+}
+
+// OnEvent registers fn to be called on every future lifecycle event. Callbacks
+// run on the observer goroutine and must not block.
+func (r *Result) OnEvent(fn func(ResultEvent)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers = append(r.handlers, fn)
+}
+
+// Events streams all past and future lifecycle events. The channel is closed
+// after the final event was delivered (final, refused, expired, canceled)
+// or when ctx is canceled.
+func (r *Result) Events(ctx context.Context) <-chan ResultEvent {
+	ch := make(chan ResultEvent)
+	go func() {
+		defer close(ch)
+		var n int
+		for {
+			r.mu.Lock()
+			pending := append([]ResultEvent(nil), r.history[n:]...)
+			notify, closed := r.notify, r.closed
+			r.mu.Unlock()
+			for _, ev := range pending {
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- ev:
+				}
+			}
+			n += len(pending)
+			if closed && len(pending) == 0 {
+				return
+			}
+			if len(pending) > 0 {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-notify:
+			}
+		}
+	}()
+	return ch
+}
+
+// emit records a lifecycle event and notifies listeners.
+func (r *Result) emit(ev ResultEvent) {
+	ev.Time = time.Now().UTC()
+	r.mu.Lock()
+	ev.Hash = r.oh
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.state = ev.State
+	r.history = append(r.history, ev)
+	close(r.notify)
+	r.notify = make(chan struct{})
+	handlers := r.handlers
+	r.mu.Unlock()
+	for _, fn := range handlers {
+		fn(ev)
+	}
+}
+
+// finish ends the lifecycle and releases all observer subscriptions. It is
+// safe to call from observer callbacks.
+func (r *Result) finish() {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.closed = true
+	close(r.notify)
+	r.notify = make(chan struct{})
+	ids := []int{r.subId, r.ttlId}
+	ids = append(ids, r.extraIds[0]...)
+	mids := append([]int{r.mempoolId}, r.extraIds[1]...)
+	r.subId, r.ttlId, r.mempoolId = 0, 0, 0
+	r.extraIds = [2][]int{}
+	r.mu.Unlock()
+
+	// unsubscribe asynchronously since callbacks hold the observer lock
+	go func() {
+		for _, id := range ids {
+			if id > 0 && r.obs != nil {
+				r.obs.Unsubscribe(id)
+			}
+		}
+		for _, id := range mids {
+			if id > 0 && r.mobs != nil {
+				r.mobs.Unsubscribe(id)
+			}
+		}
+	}()
+}
+
+// complete signals waiters with error err.
+func (r *Result) complete(err error) {
+	r.once.Do(func() {
+		r.mu.Lock()
+		r.err = err
+		r.mu.Unlock()
+		close(r.done)
+	})
+}
+
+// ListenMempool tracks the operation's mempool classification on mempool
+// observer o. Permanently refused and outdated operations fail the result
+// with ErrRefused.
+func (r *Result) ListenMempool(o *Observer) {
+	if o != nil {
+		r.mobs = o
+		id := o.SubscribeMempool(r.Hash(), r.mempoolCallback)
+		r.mu.Lock()
+		r.mempoolId = id
+		r.mu.Unlock()
+	}
+}
+
+// match returns a block observer callback for operation variant oh.
+func (r *Result) match(oh tezos.OpHash) ObserverCallback {
+	return func(block *BlockHeaderLogEntry, height int64, list, pos int, force bool) bool {
+		if list >= 0 {
+			r.mu.Lock()
+			r.oh = oh
+			r.mu.Unlock()
+		}
+		return r.callback(block, height, list, pos, force)
+	}
+}
+
+// replace tracks oh as a fee-bumped replacement of the current operation.
+// Whichever variant is included completes the result.
+func (r *Result) replace(oh tezos.OpHash) {
+	r.mu.Lock()
+	r.oh = oh
+	r.mu.Unlock()
+	r.emit(ResultEvent{State: ResultReplaced})
+	var bid, mid int
+	if r.obs != nil {
+		bid = r.obs.SubscribeReorg(oh, r.match(oh), r.reorg)
+	}
+	if r.mobs != nil {
+		mid = r.mobs.SubscribeMempool(oh, r.mempoolCallback)
+	}
+	r.mu.Lock()
+	closed := r.closed
+	if !closed {
+		r.extraIds[0] = append(r.extraIds[0], bid)
+		r.extraIds[1] = append(r.extraIds[1], mid)
+	}
+	r.mu.Unlock()
+	if closed {
+		if bid > 0 {
+			r.obs.Unsubscribe(bid)
+		}
+		if mid > 0 {
+			r.mobs.Unsubscribe(mid)
+		}
+	}
+}
+
+func (r *Result) mempoolCallback(ev *MempoolEvent) bool {
+	// ignore replaced variants and included operations
+	r.mu.Lock()
+	skip := r.block.IsValid() || !r.oh.Equal(ev.Hash)
+	r.mu.Unlock()
+	if skip {
+		return false
+	}
+	switch ev.Status {
+	case MempoolValidated, MempoolBranchDelayed:
+		r.emit(ResultEvent{State: ResultMempool, Errors: ev.Errors})
+		return false
+	case MempoolBranchRefused:
+		// may become valid again after a reorg
+		r.emit(ResultEvent{State: ResultRefused, Errors: ev.Errors})
+		return false
+	default:
+		r.emit(ResultEvent{State: ResultRefused, Errors: ev.Errors})
+		err := ErrRefused
+		if len(ev.Errors) > 0 {
+			err = refusedError{ev.Errors[0].GenericError}
+		}
+		r.complete(err)
+		r.finish()
+		return true
+	}
+}
+
+// ttlCallback expires the result when the operation was not included
+// within ttl blocks.
+func (r *Result) ttlCallback(head *BlockHeaderLogEntry, height int64, _, _ int, _ bool) bool {
+	r.mu.Lock()
+	if r.start == 0 {
+		r.start = height
+	}
+	wait := r.block.IsValid() || height-r.start < r.ttl
+	r.mu.Unlock()
+	if wait {
+		return false
+	}
+	r.emit(ResultEvent{State: ResultExpired, Block: head.Hash, Height: height})
+	r.complete(ErrTTLExceeded)
+	r.finish()
+	return true
+}
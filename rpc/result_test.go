@@ -0,0 +1,189 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func resultStates(ch <-chan ResultEvent) []ResultState {
+	var states []ResultState
+	for ev := range ch {
+		states = append(states, ev.State)
+	}
+	return states
+}
+
+func TestResultLifecycle(t *testing.T) {
+	chain, cli := newTestChain(t)
+	oh := tezos.NewOpHash(append(make([]byte, 31), 0x42))
+
+	a1 := chain.add(nil, 1)
+	obs := NewObserver().WithDelay(5 * time.Millisecond)
+	obs.Listen(cli)
+	defer obs.Close()
+	require.Eventually(t, func() bool { return obs.isKnownLocked(a1) }, time.Second, 5*time.Millisecond)
+
+	res := NewResult(oh).WithTTL(10).WithConfirmations(2)
+	res.emit(ResultEvent{State: ResultBuilt})
+	res.emit(ResultEvent{State: ResultBroadcast})
+	res.Listen(obs)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	events := res.Events(ctx)
+
+	// included, orphaned and included again on a new branch
+	a2 := chain.add(a1, 2, oh)
+	require.Eventually(t, func() bool { return res.State() == ResultIncluded }, time.Second, 5*time.Millisecond)
+	b2 := chain.add(a1, 0x12)
+	require.Eventually(t, func() bool { return res.State() == ResultReorged }, time.Second, 5*time.Millisecond)
+	b3 := chain.add(b2, 0x13, oh)
+	b4 := chain.add(b3, 0x14)
+	require.True(t, res.WaitContext(ctx))
+	require.NoError(t, res.Err())
+	chain.add(b4, 0x15)
+
+	assert.Equal(t, []ResultState{
+		ResultBuilt,
+		ResultBroadcast,
+		ResultIncluded,
+		ResultReorged,
+		ResultIncluded,
+		ResultConfirmed,
+		ResultConfirmed,
+		ResultFinal,
+	}, resultStates(events))
+	hist := res.History()
+	assert.Equal(t, a2.hash, hist[2].Block)
+	assert.Equal(t, a2.hash, hist[3].Block)
+	assert.Equal(t, b3.hash, hist[7].Block)
+	assert.Equal(t, int64(3), hist[7].Confirmations)
+	assert.False(t, hist[7].Time.IsZero())
+	assert.Equal(t, b3.level, hist[7].Height)
+}
+
+func TestResultConcurrentAccess(t *testing.T) {
+	oh := tezos.NewOpHash(append(make([]byte, 31), 0x42))
+	head := &BlockHeaderLogEntry{Hash: tezos.NewBlockHash(append(make([]byte, 31), 0x01))}
+	res := NewResult(oh).WithTTL(100).WithConfirmations(4)
+
+	// block and mempool observers run on separate goroutines while the
+	// caller polls the result
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for h := int64(10); h < 15; h++ {
+			res.callback(head, h, 3, 0, false)
+			res.ttlCallback(head, h, -1, -1, false)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 5; i++ {
+			res.mempoolCallback(&MempoolEvent{Hash: oh, Status: MempoolValidated})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 5; i++ {
+			_ = res.Confirmations()
+			_, _ = res.GetReceipt(context.Background())
+		}
+	}()
+	wg.Wait()
+	res.Wait()
+	require.NoError(t, res.Err())
+	assert.Equal(t, int64(5), res.Confirmations())
+	rec, err := res.GetReceipt(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), rec.Height)
+	assert.Equal(t, 3, rec.List)
+}
+
+func TestResultExpired(t *testing.T) {
+	chain, cli := newTestChain(t)
+	a := chain.add(nil, 1)
+	obs := NewObserver().WithDelay(5 * time.Millisecond)
+	obs.Listen(cli)
+	defer obs.Close()
+	require.Eventually(t, func() bool { return obs.isKnownLocked(a) }, time.Second, 5*time.Millisecond)
+
+	res := NewResult(tezos.NewOpHash(append(make([]byte, 31), 0x42))).WithTTL(2)
+	res.Listen(obs)
+	for i := byte(2); i < 5; i++ {
+		a = chain.add(a, i)
+		require.Eventually(t, func() bool { return obs.isKnownLocked(a) }, time.Second, 5*time.Millisecond)
+	}
+	res.Wait()
+	assert.ErrorIs(t, res.Err(), ErrTTLExceeded)
+	assert.Equal(t, ResultExpired, res.State())
+}
+
+func TestResultRefused(t *testing.T) {
+	_, cli := newTestChain(t)
+	oh := tezos.NewOpHash(append(make([]byte, 31), 0x42))
+	obs := NewObserver()
+	obs.c = cli
+	res := NewResult(oh)
+	res.ListenMempool(obs)
+
+	obs.processMempool([]*Operation{{Hash: oh}})
+	assert.Equal(t, ResultMempool, res.State())
+
+	obs.processMempool([]*Operation{{
+		Hash:   oh,
+		Errors: []OperationError{{GenericError: GenericError{ID: "proto.alpha.counter_in_the_past", Kind: "branch"}}},
+	}})
+	assert.Equal(t, ResultRefused, res.State())
+	select {
+	case <-res.Done():
+		t.Fatal("branch refused operations may become valid again")
+	default:
+	}
+
+	obs.processMempool([]*Operation{{
+		Hash:   oh,
+		Errors: []OperationError{{GenericError: GenericError{ID: "proto.alpha.balance_too_low", Kind: "permanent"}}},
+	}})
+	res.Wait()
+	assert.True(t, errors.Is(res.Err(), ErrRefused))
+	assert.Equal(t, []ResultState{ResultMempool, ResultRefused, ResultRefused}, resultStates(res.Events(context.Background())))
+}
+
+func TestResultBroadcastError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`[{"kind":"temporary","id":"failure","msg":"Error while applying operation"}]`))
+	}))
+	defer server.Close()
+	cli, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+	op := codec.NewOp().WithTransfer(tezos.MustParseAddress("tz1ZvUkxJHPTy1tC7kF8Fg1Ko8jFvSumeENg"), 1)
+
+	// the node refused the operation
+	_, err = cli.broadcast(context.Background(), op, &DefaultOptions)
+	var be *BroadcastError
+	require.True(t, errors.As(err, &be))
+	assert.Equal(t, op.Hash(), be.Hash)
+	assert.True(t, be.IsRefused())
+
+	// the outcome is unknown when the node is unreachable
+	server.Close()
+	_, err = cli.broadcast(context.Background(), op, &DefaultOptions)
+	require.True(t, errors.As(err, &be))
+	assert.False(t, be.IsRefused())
+}
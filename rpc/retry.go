@@ -0,0 +1,268 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how Client.Do retries failed requests. Requests are
+// only retried when they are idempotent, i.e. they cannot change node state
+// when repeated. Operation injection is only retried when the request
+// provably did not reach the node (connection refused, rate limited).
+type RetryPolicy struct {
+	MaxRetries  int           // max number of retries after the first attempt
+	MinBackoff  time.Duration // backoff before the first retry
+	MaxBackoff  time.Duration // upper bound for exponential backoff
+	Jitter      float64       // fraction of backoff randomized [0..1]
+	RetryStatus []int         // HTTP status codes to retry
+
+	// IsIdempotent decides whether a request is safe to repeat. When nil
+	// IsIdempotentRequest is used.
+	IsIdempotent func(*http.Request) bool
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 250 * time.Millisecond,
+	MaxBackoff: 10 * time.Second,
+	Jitter:     0.5,
+	RetryStatus: []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+func NewRetryPolicy() *RetryPolicy {
+	p := DefaultRetryPolicy
+	return &p
+}
+
+// IsIdempotentRequest returns true for requests that do not change node state.
+// Besides GET and HEAD this includes POST calls to read-only helper RPCs
+// such as simulation, forging and script execution.
+func IsIdempotentRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return strings.Contains(req.URL.Path, "/helpers/")
+	default:
+		return false
+	}
+}
+
+func (p *RetryPolicy) isIdempotent(req *http.Request) bool {
+	if p.IsIdempotent != nil {
+		return p.IsIdempotent(req)
+	}
+	return IsIdempotentRequest(req)
+}
+
+// canRetryError decides if a transport error is worth retrying.
+func (p *RetryPolicy) canRetryError(req *http.Request, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	// the request never left this host
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if !p.isIdempotent(req) {
+		return false
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	}
+	return false
+}
+
+// canRetryStatus decides if an HTTP response status is worth retrying.
+func (p *RetryPolicy) canRetryStatus(req *http.Request, status int) bool {
+	var ok bool
+	for _, v := range p.RetryStatus {
+		if v == status {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return false
+	}
+	// rate limited requests were not processed
+	return status == http.StatusTooManyRequests || p.isIdempotent(req)
+}
+
+// Backoff returns the delay before retry number n (starting at 0).
+func (p *RetryPolicy) Backoff(n int) time.Duration {
+	d := p.MinBackoff
+	for i := 0; i < n && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		if d > math.MaxInt64/2 {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 && d > 0 {
+		j := time.Duration(float64(d) * p.Jitter)
+		d = d - j + time.Duration(rand.Int63n(int64(j)+1))
+	}
+	return d
+}
+
+// parseRetryAfter reads a Retry-After header in either delay-seconds or
+// HTTP-date format.
+func parseRetryAfter(resp *http.Response) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(v); err == nil && sec > 0 {
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// RateLimiter is a client-side token bucket that limits the rate of
+// requests sent to a node.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a token bucket allowing rate requests per second
+// on average with bursts of up to burst requests.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 || l.rate <= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// Wait blocks until a request may be sent or ctx is canceled.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	return sleep(ctx, l.reserve())
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// rewind prepares a request for being sent again.
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// send executes req honoring the client's rate limiter and retry policy.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for n := 0; ; n++ {
+		if c.Limiter != nil {
+			if err := c.Limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := c.client.Do(req)
+		p := c.Retry
+		if p == nil || n >= p.MaxRetries {
+			return resp, err
+		}
+		var wait time.Duration
+		if err != nil {
+			if !p.canRetryError(req, err) {
+				return nil, err
+			}
+			wait = p.Backoff(n)
+			c.Log.Debugf("rpc: %s %s failed: %v, retrying in %s", req.Method, req.URL.Path, err, wait)
+		} else {
+			if !p.canRetryStatus(req, resp.StatusCode) {
+				return resp, nil
+			}
+			wait = p.Backoff(n)
+			if ra := parseRetryAfter(resp); ra > wait {
+				wait = ra
+			}
+			c.Log.Debugf("rpc: %s %s returned %s, retrying in %s", req.Method, req.URL.Path, resp.Status, wait)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+	}
+}
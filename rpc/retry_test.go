@@ -0,0 +1,103 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRetryTestClient(t *testing.T, h http.HandlerFunc) *Client {
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+	c, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+	p := NewRetryPolicy()
+	p.MinBackoff = time.Millisecond
+	p.MaxBackoff = 5 * time.Millisecond
+	return c.WithRetry(p)
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	var calls int32
+	c := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`"NetXdQprcVkpaWU"`))
+	})
+	id, err := c.GetChainId(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "NetXdQprcVkpaWU", id.String())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetryNeverReinjects(t *testing.T) {
+	var calls int32
+	c := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	_, err := c.BroadcastOperation(context.Background(), []byte{0x1})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadGateway, ErrorStatus(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetryAfterAndMaxRetries(t *testing.T) {
+	var calls int32
+	c := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		// rate limited injections are safe to retry
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	_, err := c.BroadcastOperation(context.Background(), []byte{0x1})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, ErrorStatus(err))
+	assert.Equal(t, int32(c.Retry.MaxRetries+1), atomic.LoadInt32(&calls))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	assert.Equal(t, 100*time.Millisecond, p.Backoff(0))
+	assert.Equal(t, 400*time.Millisecond, p.Backoff(2))
+	assert.Equal(t, time.Second, p.Backoff(10))
+
+	// zero MaxBackoff means no cap
+	uncapped := RetryPolicy{MinBackoff: 100 * time.Millisecond}
+	assert.Equal(t, 800*time.Millisecond, uncapped.Backoff(3))
+	assert.Equal(t, 102400*time.Millisecond, uncapped.Backoff(10))
+	assert.True(t, uncapped.Backoff(100) > 0)
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.Backoff(1)
+		assert.True(t, d >= 100*time.Millisecond && d <= 200*time.Millisecond, d)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(100, 2)
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, l.Wait(ctx))
+	}
+	// 2 burst tokens, then 2 more at 10ms each
+	assert.True(t, time.Since(start) >= 15*time.Millisecond)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Error(t, NewRateLimiter(0.001, 1).Wait(cctx))
+}
@@ -0,0 +1,485 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// RewardBreakdown lists rewards of a delegate in one cycle by source and
+// by recipient. Fees are always paid to the baker's spendable balance and
+// are not part of the recipient split. All amounts are in mutez.
+type RewardBreakdown struct {
+	// sources
+	Baking    int64 `json:"baking"`    // fixed baking rewards
+	Bonus     int64 `json:"bonus"`     // baking bonuses for extra attestations
+	Attesting int64 `json:"attesting"` // attestation rewards
+	Dal       int64 `json:"dal"`       // DAL attestation rewards
+	Other     int64 `json:"other"`     // nonce, VDF and denunciation rewards
+	Fees      int64 `json:"fees"`      // operation fees
+
+	// recipients
+	Own       int64 `json:"own"`       // baker's own stake, including Edge
+	Edge      int64 `json:"edge"`      // baker's edge on external staker rewards
+	Stakers   int64 `json:"stakers"`   // external stakers
+	Delegated int64 `json:"delegated"` // baker's spendable balance on behalf of delegators
+}
+
+// Rewards returns the sum of all protocol rewards excluding fees.
+func (b RewardBreakdown) Rewards() int64 {
+	return b.Baking + b.Bonus + b.Attesting + b.Dal + b.Other
+}
+
+// Total returns the sum of all rewards and fees.
+func (b RewardBreakdown) Total() int64 {
+	return b.Rewards() + b.Fees
+}
+
+// Sub returns the difference b - a per field.
+func (b RewardBreakdown) Sub(a RewardBreakdown) RewardBreakdown {
+	return RewardBreakdown{
+		Baking:    b.Baking - a.Baking,
+		Bonus:     b.Bonus - a.Bonus,
+		Attesting: b.Attesting - a.Attesting,
+		Dal:       b.Dal - a.Dal,
+		Other:     b.Other - a.Other,
+		Fees:      b.Fees - a.Fees,
+		Own:       b.Own - a.Own,
+		Edge:      b.Edge - a.Edge,
+		Stakers:   b.Stakers - a.Stakers,
+		Delegated: b.Delegated - a.Delegated,
+	}
+}
+
+// Add adds a to b per field.
+func (b *RewardBreakdown) Add(a RewardBreakdown) {
+	b.Baking += a.Baking
+	b.Bonus += a.Bonus
+	b.Attesting += a.Attesting
+	b.Dal += a.Dal
+	b.Other += a.Other
+	b.Fees += a.Fees
+	b.Own += a.Own
+	b.Edge += a.Edge
+	b.Stakers += a.Stakers
+	b.Delegated += a.Delegated
+}
+
+// AddBalanceUpdates accounts all rewards and fees paid to delegate in list,
+// usually the balance updates of a block's metadata.
+func (b *RewardBreakdown) AddBalanceUpdates(delegate tezos.Address, list BalanceUpdates) {
+	for _, group := range list.groups() {
+		for i, u := range group {
+			if u.Change <= 0 {
+				continue
+			}
+			cp := counterpart(group, i)
+			if cp == nil {
+				continue
+			}
+			// recipient
+			var own, edge, stakers, liquid bool
+			switch {
+			case u.Kind == CONTRACT:
+				liquid = u.Contract.Equal(delegate)
+			case u.Kind == "freezer" && u.Category == "deposits":
+				switch {
+				case u.Staker.BakerEdge.Equal(delegate):
+					own, edge = true, true
+				case u.Staker.BakerOwnStake.Equal(delegate), u.Staker.Baker.Equal(delegate):
+					own = true
+				case u.Staker.Delegate.Equal(delegate) && !u.Staker.Contract.IsValid():
+					stakers = true
+				case u.Delegate.Equal(delegate):
+					own = true
+				}
+			}
+			if !own && !stakers && !liquid {
+				continue
+			}
+
+			// source
+			switch {
+			case cp.Kind == "accumulator" && cp.Category == "block fees":
+				if liquid {
+					b.Fees += u.Change
+				}
+				continue
+			case cp.Kind != "minted":
+				continue
+			case cp.Category == "baking rewards":
+				b.Baking += u.Change
+			case cp.Category == "baking bonuses":
+				b.Bonus += u.Change
+			case cp.Category == "attesting rewards", cp.Category == "endorsing rewards":
+				b.Attesting += u.Change
+			case cp.Category == "dal attesting rewards":
+				b.Dal += u.Change
+			case cp.Category == "nonce revelation rewards", cp.Category == "double signing evidence rewards":
+				b.Other += u.Change
+			default:
+				continue
+			}
+			switch {
+			case own:
+				b.Own += u.Change
+				if edge {
+					b.Edge += u.Change
+				}
+			case stakers:
+				b.Stakers += u.Change
+			case liquid:
+				b.Delegated += u.Change
+			}
+		}
+	}
+}
+
+// RewardEstimate is the expected income of a delegate in a cycle together
+// with the data it was derived from.
+type RewardEstimate struct {
+	Delegate tezos.Address `json:"delegate"`
+	Cycle    int64         `json:"cycle"`
+
+	// rights
+	Blocks              int64 `json:"blocks"`                // round 0 baking rights
+	AttestingPower      int64 `json:"attesting_power"`       // delegate attesting power in cycle
+	TotalAttestingPower int64 `json:"total_attesting_power"` // attesting power of all delegates
+
+	// stake
+	OwnStaked      int64 `json:"own_staked"`
+	ExternalStaked int64 `json:"external_staked"` // capped by the staking limit
+	Delegated      int64 `json:"delegated"`       // including overstaked tez, capped by the delegation limit
+
+	// parameters
+	Issuance       IssuanceParameters `json:"issuance"`
+	Staking        StakingParameters  `json:"staking"`
+	BlocksPerCycle int64              `json:"blocks_per_cycle"`
+	CommitteeSize  int64              `json:"committee_size"`  // attestation slots per block (until v023)
+	Threshold      int64              `json:"threshold"`       // slots required for a block (until v023)
+	DalShards      int64              `json:"dal_shards"`      // DAL shards per level
+	DelegateWeight int64              `json:"delegate_weight"` // edge of staking over delegation
+	DelegationMax  int64              `json:"delegation_max"`  // limit of delegation over own stake
+	FeesPerBlock   int64              `json:"fees_per_block"`  // assumed average fees
+
+	Expected RewardBreakdown `json:"expected"`
+}
+
+// Share returns the delegate's share of total attesting power.
+func (e *RewardEstimate) Share() float64 {
+	if e.TotalAttestingPower == 0 {
+		return 0
+	}
+	return float64(e.AttestingPower) / float64(e.TotalAttestingPower)
+}
+
+// limit applies the staking limit and the delegation limit to stake.
+// Overstaked tez count as delegated, delegated tez above the delegation
+// limit earn no rights.
+func (e *RewardEstimate) limit() {
+	if l := mulDiv(e.OwnStaked, e.Staking.Limit, 1_000_000); e.ExternalStaked > l {
+		e.Delegated += e.ExternalStaked - l
+		e.ExternalStaked = l
+	}
+	if e.DelegationMax > 0 {
+		if l := e.DelegationMax * e.OwnStaked; e.Delegated > l {
+			e.Delegated = l
+		}
+	}
+}
+
+// compute derives expected rewards from rights, stake and parameters. It
+// assumes all rights are used and all blocks are fully attested.
+func (e *RewardEstimate) compute() {
+	var r RewardBreakdown
+	iss := e.Issuance
+
+	// baking
+	r.Baking = e.Blocks * iss.BakingReward
+	switch {
+	case iss.BakingBonusPerBlock != nil:
+		r.Bonus = e.Blocks * *iss.BakingBonusPerBlock
+	case iss.BakingBonusPerSlot != nil && e.CommitteeSize > e.Threshold:
+		r.Bonus = e.Blocks * *iss.BakingBonusPerSlot * (e.CommitteeSize - e.Threshold)
+	}
+
+	// attesting, paid at cycle end in proportion to attesting power
+	switch {
+	case iss.AttestingRewardPerBlock != nil:
+		r.Attesting = mulDiv(*iss.AttestingRewardPerBlock*e.BlocksPerCycle, e.AttestingPower, e.TotalAttestingPower)
+	case iss.AttestingRewardPerSlot != nil:
+		r.Attesting = *iss.AttestingRewardPerSlot * e.AttestingPower
+	}
+
+	// DAL shards are assigned in proportion to attesting power
+	if iss.DalAttestingRewardPerShard != nil {
+		r.Dal = mulDiv(*iss.DalAttestingRewardPerShard*e.DalShards*e.BlocksPerCycle, e.AttestingPower, e.TotalAttestingPower)
+	}
+
+	r.Fees = e.Blocks * e.FeesPerBlock
+	r.Own, r.Edge, r.Stakers, r.Delegated = e.split(r.Rewards())
+	e.Expected = r
+}
+
+// split distributes rewards between the baker's own stake, external
+// stakers and delegated tez. Staked tez weigh DelegateWeight times more
+// than delegated tez, external stakers pay the baker's edge.
+func (e *RewardEstimate) split(rewards int64) (own, edge, stakers, delegated int64) {
+	staked := e.OwnStaked + e.ExternalStaked
+	k := e.DelegateWeight
+	if k <= 0 {
+		k = 2
+	}
+	stakedPart := mulDiv(rewards, staked*k, staked*k+e.Delegated)
+	extPart := mulDiv(stakedPart, e.ExternalStaked, staked)
+	edge = mulDiv(extPart, e.Staking.Edge, 1_000_000_000)
+	own = stakedPart - extPart + edge
+	stakers = extPart - edge
+	delegated = rewards - stakedPart
+	return
+}
+
+// mulDiv returns a * b / c without intermediate overflow and 0 for c == 0.
+func mulDiv(a, b, c int64) int64 {
+	if c == 0 {
+		return 0
+	}
+	x := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	return x.Quo(x, big.NewInt(c)).Int64()
+}
+
+// RewardComparison contrasts expected and actual rewards of a past cycle.
+type RewardComparison struct {
+	Estimate *RewardEstimate `json:"estimate"`
+	Actual   RewardBreakdown `json:"actual"`
+}
+
+// Diff returns actual minus expected rewards per field.
+func (c *RewardComparison) Diff() RewardBreakdown {
+	return c.Actual.Sub(c.Estimate.Expected)
+}
+
+// Efficiency returns actual over expected rewards excluding fees.
+func (c *RewardComparison) Efficiency() float64 {
+	exp := c.Estimate.Expected.Rewards()
+	if exp == 0 {
+		return 0
+	}
+	return float64(c.Actual.Rewards()) / float64(exp)
+}
+
+// RewardEstimator combines issuance, rights, staking parameters and stake
+// into expected rewards per delegate and cycle.
+type RewardEstimator struct {
+	c *Client
+
+	// FeesPerBlock is the average fee income assumed per baked block.
+	FeesPerBlock int64
+	// Workers is the number of parallel block fetchers used by Actual.
+	Workers int
+}
+
+func NewRewardEstimator(c *Client) *RewardEstimator {
+	return &RewardEstimator{
+		c:       c,
+		Workers: 4,
+	}
+}
+
+// rewardConstants are protocol constants not mapped into tezos.Params.
+type rewardConstants struct {
+	CommitteeSize  int64 `json:"consensus_committee_size"`
+	Threshold      int64 `json:"consensus_threshold"`      // -v022
+	ThresholdSize  int64 `json:"consensus_threshold_size"` // v023+
+	DelegateWeight int64 `json:"edge_of_staking_over_delegation"`
+	DelegationMax  int64 `json:"limit_of_delegation_over_baking"`
+	Dal            struct {
+		NumberOfShards int64 `json:"number_of_shards"`
+	} `json:"dal_parametric"`
+}
+
+// Estimate returns the expected rewards of delegate in cycle. Future cycles
+// are estimated from current stake and are limited to cycles with known
+// rights. Past cycles are estimated from the state at cycle start.
+func (e *RewardEstimator) Estimate(ctx context.Context, delegate tezos.Address, cycle int64) (*RewardEstimate, error) {
+	p, err := e.c.GetParams(ctx, Head)
+	if err != nil {
+		return nil, err
+	}
+	head, err := e.c.GetBlockHeader(ctx, Head)
+	if err != nil {
+		return nil, err
+	}
+	var id BlockID = Head
+	if cycle <= p.CycleFromHeight(head.Level) {
+		id = BlockLevel(p.AtCycle(cycle).CycleStartHeight(cycle))
+	}
+
+	var con rewardConstants
+	if err := e.c.GetCustomConstants(ctx, id, &con); err != nil {
+		return nil, err
+	}
+	est := &RewardEstimate{
+		Delegate:       delegate,
+		Cycle:          cycle,
+		BlocksPerCycle: p.BlocksPerCycle,
+		CommitteeSize:  con.CommitteeSize,
+		Threshold:      con.ThresholdSize,
+		DalShards:      con.Dal.NumberOfShards,
+		DelegateWeight: con.DelegateWeight,
+		DelegationMax:  con.DelegationMax,
+		FeesPerBlock:   e.FeesPerBlock,
+	}
+	if est.Threshold == 0 {
+		est.Threshold = con.Threshold
+	}
+
+	// issuance
+	iss, err := e.c.GetIssuance(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var found bool
+	for _, v := range iss {
+		if v.Cycle == cycle {
+			est.Issuance, found = v, true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("rewards: no issuance for cycle %d", cycle)
+	}
+
+	// rights
+	bake, err := e.c.ListBakingRightsCycle(ctx, id, cycle, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range bake {
+		if r.Round == 0 && r.Priority == 0 && r.Delegate.Equal(delegate) {
+			est.Blocks++
+		}
+	}
+	attest, err := e.c.ListEndorsingRightsCycle(ctx, id, cycle)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range attest {
+		power := int64(r.AttestationPower)
+		if power == 0 {
+			power = int64(r.Power())
+		}
+		est.TotalAttestingPower += power
+		if r.Delegate.Equal(delegate) {
+			est.AttestingPower += power
+		}
+	}
+
+	// stake and staking parameters
+	d, err := e.c.GetDelegate(ctx, delegate, id)
+	if err != nil {
+		return nil, err
+	}
+	est.Staking = d.ActiveStakingParameters
+	for _, v := range d.PendingStakingParameters {
+		if v.Cycle <= cycle {
+			est.Staking = v.Parameters
+		}
+	}
+	est.OwnStaked = int64(d.OwnStaked)
+	est.ExternalStaked = int64(d.ExternalStaked)
+	est.Delegated = int64(d.TotalDelegated)
+	est.limit()
+	est.compute()
+	return est, nil
+}
+
+// Actual returns rewards and fees paid to delegate in cycle from the balance
+// updates of all block metadata in the cycle.
+func (e *RewardEstimator) Actual(ctx context.Context, delegate tezos.Address, cycle int64) (RewardBreakdown, error) {
+	p, err := e.c.GetParams(ctx, Head)
+	if err != nil {
+		return RewardBreakdown{}, err
+	}
+	p = p.AtCycle(cycle)
+	var (
+		from, to = p.CycleStartHeight(cycle), p.CycleEndHeight(cycle)
+		levels   = make(chan int64)
+		results  = make([]RewardBreakdown, e.workers())
+		wg       sync.WaitGroup
+		once     sync.Once
+		failed   error
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fail := func(err error) {
+		once.Do(func() {
+			failed = err
+			cancel()
+		})
+	}
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for level := range levels {
+				meta, err := e.c.GetBlockMetadata(ctx, BlockLevel(level))
+				if err != nil {
+					fail(fmt.Errorf("rewards: block %d: %w", level, err))
+					return
+				}
+				results[i].AddBalanceUpdates(delegate, meta.BalanceUpdates)
+			}
+		}(i)
+	}
+	func() {
+		defer close(levels)
+		for level := from; level <= to; level++ {
+			select {
+			case <-ctx.Done():
+				return
+			case levels <- level:
+			}
+		}
+	}()
+	wg.Wait()
+
+	if failed != nil {
+		return RewardBreakdown{}, failed
+	}
+	if err := ctx.Err(); err != nil {
+		return RewardBreakdown{}, err
+	}
+	var res RewardBreakdown
+	for _, v := range results {
+		res.Add(v)
+	}
+	return res, nil
+}
+
+// Compare estimates rewards of delegate in past cycle and compares them
+// against actual payments.
+func (e *RewardEstimator) Compare(ctx context.Context, delegate tezos.Address, cycle int64) (*RewardComparison, error) {
+	est, err := e.Estimate(ctx, delegate, cycle)
+	if err != nil {
+		return nil, err
+	}
+	act, err := e.Actual(ctx, delegate, cycle)
+	if err != nil {
+		return nil, err
+	}
+	return &RewardComparison{Estimate: est, Actual: act}, nil
+}
+
+func (e *RewardEstimator) workers() int {
+	if e.Workers <= 0 {
+		return 1
+	}
+	return e.Workers
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+const (
+	rewardsBaker = "tz1aDiEJf9ztRrAJEXZfcG3CKimoKsGhwVAi"
+	rewardsOther = "tz1YFxWGfE7K8wQkKBVerB21HbNEiLpA2ch9"
+)
+
+func int64p(v int64) *int64 {
+	return &v
+}
+
+func TestRewardEstimate(t *testing.T) {
+	est := &RewardEstimate{
+		Blocks:              10,
+		AttestingPower:      250,
+		TotalAttestingPower: 10000,
+		OwnStaked:           1_000_000,
+		ExternalStaked:      1_000_000,
+		Delegated:           4_000_000,
+		Issuance: IssuanceParameters{
+			BakingReward:               1000,
+			BakingBonusPerBlock:        int64p(500),
+			AttestingRewardPerBlock:    int64p(2000),
+			DalAttestingRewardPerShard: int64p(3),
+		},
+		Staking:        StakingParameters{Limit: 5_000_000, Edge: 100_000_000}, // 10% edge
+		BlocksPerCycle: 400,
+		DalShards:      100,
+		DelegateWeight: 2,
+		FeesPerBlock:   70,
+	}
+	est.compute()
+	r := est.Expected
+	assert.Equal(t, int64(10_000), r.Baking)
+	assert.Equal(t, int64(5_000), r.Bonus)
+	assert.Equal(t, int64(20_000), r.Attesting) // 2000 * 400 * 2.5%
+	assert.Equal(t, int64(3_000), r.Dal)        // 3 * 100 * 400 * 2.5%
+	assert.Equal(t, int64(700), r.Fees)
+	assert.Equal(t, int64(38_000), r.Rewards())
+	assert.Equal(t, 0.025, est.Share())
+
+	// staked weight 2 * 2M = 4M, delegated weight 4M: half goes to stake
+	assert.Equal(t, int64(19_000), r.Delegated)
+	assert.Equal(t, int64(950), r.Edge)
+	assert.Equal(t, int64(9_500+950), r.Own)
+	assert.Equal(t, int64(9_500-950), r.Stakers)
+	assert.Equal(t, r.Rewards(), r.Own+r.Stakers+r.Delegated)
+
+	// v023 rewards per slot
+	est.Issuance = IssuanceParameters{
+		BakingReward:           1000,
+		BakingBonusPerSlot:     int64p(2),
+		AttestingRewardPerSlot: int64p(3),
+	}
+	est.CommitteeSize, est.Threshold = 7000, 4667
+	est.compute()
+	assert.Equal(t, int64(10*2*2333), est.Expected.Bonus)
+	assert.Equal(t, int64(750), est.Expected.Attesting)
+	assert.Equal(t, int64(0), est.Expected.Dal)
+}
+
+func TestRewardEstimateLimits(t *testing.T) {
+	type row struct {
+		Own, External, Delegated int64
+	}
+	for _, v := range []struct {
+		in, out row
+	}{
+		// within limits
+		{row{1_000_000, 1_000_000, 4_000_000}, row{1_000_000, 1_000_000, 4_000_000}},
+		// overstaked tez count as delegated
+		{row{1_000_000, 7_000_000, 1_000_000}, row{1_000_000, 5_000_000, 3_000_000}},
+		// overdelegated tez are ignored
+		{row{1_000_000, 1_000_000, 12_000_000}, row{1_000_000, 1_000_000, 9_000_000}},
+		// overstaked tez are ignored when overdelegated
+		{row{1_000_000, 7_000_000, 8_000_000}, row{1_000_000, 5_000_000, 9_000_000}},
+	} {
+		est := &RewardEstimate{
+			OwnStaked:      v.in.Own,
+			ExternalStaked: v.in.External,
+			Delegated:      v.in.Delegated,
+			Staking:        StakingParameters{Limit: 5_000_000},
+			DelegationMax:  9,
+		}
+		est.limit()
+		assert.Equal(t, v.out, row{est.OwnStaked, est.ExternalStaked, est.Delegated})
+	}
+
+	// an overdelegated baker earns as much as one at the limit
+	est := &RewardEstimate{
+		Blocks:         10,
+		OwnStaked:      1_000_000,
+		Delegated:      20_000_000,
+		Issuance:       IssuanceParameters{BakingReward: 1100},
+		DelegateWeight: 2,
+		DelegationMax:  9,
+	}
+	est.limit()
+	est.compute()
+	assert.Equal(t, int64(9_000_000), est.Delegated)
+	assert.Equal(t, int64(2_000), est.Expected.Own)       // 11000 * 2M / 11M
+	assert.Equal(t, int64(9_000), est.Expected.Delegated) // 11000 * 9M / 11M
+}
+
+func TestRewardActual(t *testing.T) {
+	baker := tezos.MustParseAddress(rewardsBaker)
+	raw := `[
+		{"kind":"accumulator","category":"block fees","change":"-1000","origin":"block"},
+		{"kind":"contract","contract":"` + rewardsBaker + `","change":"1000","origin":"block"},
+		{"kind":"minted","category":"baking rewards","change":"-5000","origin":"block"},
+		{"kind":"freezer","category":"deposits","staker":{"baker_own_stake":"` + rewardsBaker + `"},"change":"5000","origin":"block"},
+		{"kind":"minted","category":"baking rewards","change":"-300","origin":"block"},
+		{"kind":"freezer","category":"deposits","staker":{"baker_edge":"` + rewardsBaker + `"},"change":"300","origin":"block"},
+		{"kind":"minted","category":"baking rewards","change":"-2700","origin":"block"},
+		{"kind":"freezer","category":"deposits","staker":{"delegate":"` + rewardsBaker + `"},"change":"2700","origin":"block"},
+		{"kind":"minted","category":"baking rewards","change":"-8000","origin":"block"},
+		{"kind":"contract","contract":"` + rewardsBaker + `","change":"8000","origin":"block"},
+		{"kind":"minted","category":"baking bonuses","change":"-400","origin":"block"},
+		{"kind":"contract","contract":"` + rewardsBaker + `","change":"400","origin":"block"},
+		{"kind":"minted","category":"attesting rewards","change":"-600","origin":"block"},
+		{"kind":"contract","contract":"` + rewardsBaker + `","change":"600","origin":"block"},
+		{"kind":"minted","category":"dal attesting rewards","change":"-60","origin":"block"},
+		{"kind":"contract","contract":"` + rewardsBaker + `","change":"60","origin":"block"},
+		{"kind":"minted","category":"baking rewards","change":"-9999","origin":"block"},
+		{"kind":"contract","contract":"` + rewardsOther + `","change":"9999","origin":"block"},
+		{"kind":"minted","category":"attesting rewards","change":"-50","origin":"block"},
+		{"kind":"burned","category":"lost attesting rewards","delegate":"` + rewardsBaker + `","participation":true,"change":"50","origin":"block"}
+	]`
+	var list BalanceUpdates
+	require.NoError(t, json.Unmarshal([]byte(raw), &list))
+
+	var r RewardBreakdown
+	r.AddBalanceUpdates(baker, list)
+	assert.Equal(t, RewardBreakdown{
+		Baking:    16_000,
+		Bonus:     400,
+		Attesting: 600,
+		Dal:       60,
+		Fees:      1_000,
+		Own:       5_300,
+		Edge:      300,
+		Stakers:   2_700,
+		Delegated: 9_060,
+	}, r)
+	assert.Equal(t, int64(18_060), r.Total())
+
+	cmp := &RewardComparison{
+		Estimate: &RewardEstimate{Expected: RewardBreakdown{Baking: 16_000, Bonus: 400, Attesting: 1_200, Dal: 60, Fees: 500}},
+		Actual:   r,
+	}
+	assert.Equal(t, int64(-600), cmp.Diff().Attesting)
+	assert.Equal(t, int64(500), cmp.Diff().Fees)
+	assert.InDelta(t, 17_060.0/17_660.0, cmp.Efficiency(), 1e-9)
+}
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 
 	"github.com/trilitech/tzgo/codec"
@@ -52,16 +53,18 @@ var (
 )
 
 type CallOptions struct {
-	Confirmations     int64         // number of confirmations to wait after broadcast
-	MaxFee            int64         // max acceptable fee, optional (default = 0)
-	TTL               int64         // max lifetime for operations in blocks
-	IgnoreLimits      bool          // ignore simulated limits and use user-defined limits from op
-	ExtraGasMargin    int64         // safety margin in case simulation underestimates future usage
-	SimulationBlockID BlockID       // custom block id to simulate operation (default is head, use to select a past block)
-	SimulationOffset  int64         // custom block offset for future block simulations
-	Signer            signer.Signer // optional signer interface to use for signing the transaction
-	Sender            tezos.Address // optional address to sign for (use when signer manages multiple addresses)
-	Observer          *Observer     // optional custom block observer for waiting on confirmations
+	Confirmations     int64          // number of confirmations to wait after broadcast
+	MaxFee            int64          // max acceptable fee, optional (default = 0)
+	TTL               int64          // max lifetime for operations in blocks
+	IgnoreLimits      bool           // ignore simulated limits and use user-defined limits from op
+	ExtraGasMargin    int64          // safety margin in case simulation underestimates future usage
+	SimulationBlockID BlockID        // custom block id to simulate operation (default is head, use to select a past block)
+	SimulationOffset  int64          // custom block offset for future block simulations
+	Signer            signer.Signer  // optional signer interface to use for signing the transaction
+	Sender            tezos.Address  // optional address to sign for (use when signer manages multiple addresses)
+	Observer          *Observer      // optional custom block observer for waiting on confirmations
+	Replace           *ReplacePolicy // optional fee bumping for operations stuck in the mempool
+	WatchMempool      bool           // track mempool classification and fail on refusal (always on with Replace)
 }
 
 var DefaultOptions = CallOptions{
@@ -280,42 +283,98 @@ func (c *Client) Broadcast(ctx context.Context, o *codec.Op) (tezos.OpHash, erro
 	return c.BroadcastOperation(ctx, o.Bytes())
 }
 
-// Send is a convenience wrapper for sending operations. It auto-completes gas and storage limit,
-// ensures minimum fees are set, protects against fee overpayment, signs and broadcasts the final
-// operation and waits for a defined number of confirmations.
-func (c *Client) Send(ctx context.Context, op *codec.Op, opts *CallOptions) (*Receipt, error) {
-	if opts == nil {
-		opts = &DefaultOptions
-	}
-
-	signer := c.Signer
+// sender resolves the signer, sender address and sender key for opts.
+func (c *Client) sender(ctx context.Context, opts *CallOptions) (signer.Signer, tezos.Address, tezos.Key, error) {
+	s := c.Signer
 	if opts.Signer != nil {
-		signer = opts.Signer
+		s = opts.Signer
 	}
 
 	// identify the sender address for signing the message
 	addr := opts.Sender
 	if !addr.IsValid() {
-		addrs, err := signer.ListAddresses(ctx)
+		addrs, err := s.ListAddresses(ctx)
 		if err != nil {
-			return nil, err
+			return nil, addr, tezos.InvalidKey, err
 		}
 		addr = addrs[0]
 	}
 
-	key, err := signer.GetKey(ctx, addr)
+	key, err := s.GetKey(ctx, addr)
+	if err != nil {
+		return nil, addr, tezos.InvalidKey, err
+	}
+	return s, addr, key, nil
+}
+
+// Send is a convenience wrapper for sending operations. It auto-completes gas and storage limit,
+// ensures minimum fees are set, protects against fee overpayment, signs and broadcasts the final
+// operation and waits for a defined number of confirmations.
+func (c *Client) Send(ctx context.Context, op *codec.Op, opts *CallOptions) (*Receipt, error) {
+	res, err := c.SendAsync(ctx, op, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	// use custom observer when provided
-	mon := c.BlockObserver
-	if opts.Observer != nil {
-		mon = opts.Observer
+	// wait for confirmations
+	res.WaitContext(ctx)
+	if err := res.Err(); err != nil {
+		return nil, err
 	}
 
-	// ensure block observer is running
-	mon.Listen(c)
+	// return receipt
+	return res.GetReceipt(ctx)
+}
+
+// BroadcastError is returned by SendAsync when injecting a signed operation
+// failed. Unless the node refused the operation it may still have reached
+// the network and later be included under Hash.
+type BroadcastError struct {
+	Hash tezos.OpHash
+	Err  error
+}
+
+func (e *BroadcastError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BroadcastError) Unwrap() error {
+	return e.Err
+}
+
+// IsRefused returns true when the node rejected the operation.
+func (e *BroadcastError) IsRefused() bool {
+	var rpcErr RPCError
+	return errors.As(e.Err, &rpcErr)
+}
+
+// SendAsync is like Send, but returns a Result right after broadcast. Use the
+// Result to wait for confirmations or to follow lifecycle events. Errors
+// after signing and journaling are of type *BroadcastError.
+func (c *Client) SendAsync(ctx context.Context, op *codec.Op, opts *CallOptions) (*Result, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+
+	signer, addr, key, err := c.sender(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// use custom observer when provided and ensure it is running
+	mon := c.observer(opts)
+
+	// serialise sends per source when requested
+	var res *Result
+	unlock := func() {}
+	if c.Counters != nil {
+		unlock = c.Counters.Lock(key.Address())
+		defer func() {
+			if res == nil {
+				unlock()
+			}
+		}()
+	}
 
 	// set source and params on all ops
 	op.WithSource(key.Address()).WithParams(c.Params)
@@ -365,9 +424,26 @@ func (c *Client) Send(ctx context.Context, op *codec.Op, opts *CallOptions) (*Re
 		}
 	}
 
+	// assign locally managed counters after simulation against chain state
+	var first, last int64
+	if c.Counters != nil {
+		first, err = c.Counters.Assign(ctx, key.Address(), op)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range op.Contents {
+			if n := v.GetCounter(); n > last {
+				last = n
+			}
+		}
+	}
+
 	// sign digest
 	sig, err := signer.SignOperation(ctx, addr, op)
 	if err != nil {
+		if c.Counters != nil {
+			c.Counters.Fail(key.Address(), first)
+		}
 		return nil, err
 	}
 	op.WithSignature(sig)
@@ -378,24 +454,93 @@ func (c *Client) Send(ctx context.Context, op *codec.Op, opts *CallOptions) (*Re
 		c.Log.Tracef("Broadcast: %s", string(buf))
 	})
 
+	// journal the signed operation before injection
+	var entry *JournalEntry
+	if c.Journal != nil {
+		entry, err = c.Journal.record(op)
+		if err != nil {
+			if c.Counters != nil {
+				c.Counters.Fail(key.Address(), first)
+			}
+			return nil, err
+		}
+	}
+
 	// broadcast
-	hash, err := c.Broadcast(ctx, op)
+	res, err = c.broadcast(ctx, op, opts)
 	if err != nil {
+		if entry != nil {
+			c.Journal.broadcastFailed(entry, err)
+		}
+		if c.Counters != nil {
+			c.Counters.release(key.Address(), first, last, err)
+		}
 		return nil, err
 	}
+	if entry != nil {
+		c.Journal.track(entry, res)
+	}
 
-	// wait for confirmations
-	res := NewResult(hash).WithTTL(op.TTL).WithConfirmations(opts.Confirmations)
+	// release counters and the per-source lock once the result is known
+	if c.Counters != nil {
+		go func() {
+			<-res.Done()
+			c.Counters.release(key.Address(), first, last, res.Err())
+			unlock()
+		}()
+	}
 
-	// wait for confirmations
-	res.Listen(mon)
-	res.WaitContext(ctx)
-	if err := res.Err(); err != nil {
-		return nil, err
+	// replace stuck operations with higher fees
+	if opts.Replace != nil {
+		p := *opts.Replace
+		if p.MaxFee == 0 {
+			p.MaxFee = opts.MaxFee
+		}
+		go c.replaceStuck(ctx, res, op, signer, addr, &p, mon, entry)
 	}
 
-	// return receipt
-	return res.GetReceipt(ctx)
+	c.watch(res, mon, opts)
+	return res, nil
+}
+
+// observer returns the block observer for opts and ensures it is running.
+func (c *Client) observer(opts *CallOptions) *Observer {
+	mon := c.BlockObserver
+	if opts.Observer != nil {
+		mon = opts.Observer
+	}
+	mon.Listen(c)
+	return mon
+}
+
+// broadcast injects the signed operation op and returns a Result that
+// has seen the built and broadcast events. Failed injections complete the
+// result and are returned as *BroadcastError. Only injections the node
+// rejected are reported as refused.
+func (c *Client) broadcast(ctx context.Context, op *codec.Op, opts *CallOptions) (*Result, error) {
+	res := NewResult(op.Hash()).WithTTL(op.TTL).WithConfirmations(opts.Confirmations)
+	res.emit(ResultEvent{State: ResultBuilt})
+	if _, err := c.Broadcast(ctx, op); err != nil {
+		berr := &BroadcastError{Hash: op.Hash(), Err: err}
+		if berr.IsRefused() {
+			res.emit(ResultEvent{State: ResultRefused})
+		}
+		res.complete(berr)
+		res.finish()
+		return nil, berr
+	}
+	res.emit(ResultEvent{State: ResultBroadcast})
+	return res, nil
+}
+
+// watch tracks res on block observer mon and, when requested, on the
+// mempool observer.
+func (c *Client) watch(res *Result, mon *Observer, opts *CallOptions) {
+	if opts.WatchMempool || opts.Replace != nil {
+		c.MempoolObserver.ListenMempool(c)
+		res.ListenMempool(c.MempoolObserver)
+	}
+	res.Listen(mon)
 }
 
 // RunOperation simulates executing an operation without requiring a valid signature.
@@ -0,0 +1,211 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// UnstakeSlashingPeriod is the number of cycles after the consensus rights
+// delay during which unstaked funds remain slashable.
+const UnstakeSlashingPeriod = 2
+
+var ErrNothingToFinalize = errors.New("rpc: no finalizable unstaked funds")
+
+// UnstakeRequest is an unstake request created at cycle.
+type UnstakeRequest struct {
+	Delegate tezos.Address `json:"delegate"`
+	Cycle    int64         `json:"cycle"`
+	Amount   int64         `json:"amount,string"`
+}
+
+// UnstakeRequests holds an account's finalizable and still frozen unstake
+// requests.
+type UnstakeRequests struct {
+	Finalizable []UnstakeRequest `json:"finalizable"`
+	Unfinalized struct {
+		Delegate tezos.Address    `json:"delegate"`
+		Requests []UnstakeRequest `json:"requests"`
+	} `json:"unfinalized"`
+}
+
+// GetUnstakeRequests returns unstake requests of an account at block id.
+func (c *Client) GetUnstakeRequests(ctx context.Context, addr tezos.Address, id BlockID) (*UnstakeRequests, error) {
+	u := fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/unstake_requests", id, addr)
+	var req *UnstakeRequests
+	if err := c.Get(ctx, u, &req); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		req = &UnstakeRequests{}
+	}
+	return req, nil
+}
+
+// GetStakedBalance returns the staked balance of an account at block id.
+func (c *Client) GetStakedBalance(ctx context.Context, addr tezos.Address, id BlockID) (int64, error) {
+	u := fmt.Sprintf("chains/main/blocks/%s/context/contracts/%s/staked_balance", id, addr)
+	var bal *Int64orString
+	if err := c.Get(ctx, u, &bal); err != nil || bal == nil {
+		return 0, err
+	}
+	return int64(*bal), nil
+}
+
+// UnstakeUnlock is a pending unstake request and its predicted unlock.
+type UnstakeUnlock struct {
+	Cycle  int64     `json:"cycle"`  // request cycle
+	Unlock int64     `json:"unlock"` // first cycle funds become finalizable
+	Amount int64     `json:"amount"`
+	Time   time.Time `json:"time"` // estimated start of unlock cycle
+}
+
+// StakingInfo summarizes an account's staked, unstaked and finalizable funds.
+// Unstake amounts are requested amounts and do not reflect slashing.
+type StakingInfo struct {
+	Address   tezos.Address `json:"address"`
+	Delegate  tezos.Address `json:"delegate"`
+	Height    int64         `json:"height"`
+	Cycle     int64         `json:"cycle"`
+	Spendable int64         `json:"spendable"`
+	Staked    int64         `json:"staked"`
+
+	// unstaked funds
+	Unstaked    int64           `json:"unstaked"`    // frozen, pending unlock
+	Finalizable int64           `json:"finalizable"` // ready to finalize now
+	Pending     []UnstakeUnlock `json:"pending"`     // sorted by unlock and request cycle
+
+	// delegate staking setup, nil when not delegated
+	Params *StakingParameters `json:"params,omitempty"`
+}
+
+// NextUnlock returns the earliest pending unlock or nil.
+func (i *StakingInfo) NextUnlock() *UnstakeUnlock {
+	if len(i.Pending) == 0 {
+		return nil
+	}
+	return &i.Pending[0]
+}
+
+// CanFinalize returns true when unstaked funds can be finalized now.
+func (i *StakingInfo) CanFinalize() bool {
+	return i.Finalizable > 0
+}
+
+// FinalizeOp returns an operation that moves finalizable funds back to the
+// spendable balance or nil when nothing is ready.
+func (i *StakingInfo) FinalizeOp() *codec.Op {
+	if !i.CanFinalize() {
+		return nil
+	}
+	return codec.NewOp().WithSource(i.Address).WithFinalizeUnstake()
+}
+
+// UnstakeUnlockCycle returns the cycle in which an unstake request created in
+// cycle becomes finalizable.
+func UnstakeUnlockCycle(p *tezos.Params, cycle int64) int64 {
+	return cycle + p.ConsensusRightsDelay + UnstakeSlashingPeriod
+}
+
+// GetStakingInfo returns staked, unstaked and finalizable funds of an account
+// at block id and predicts when pending unstake requests unlock.
+func (c *Client) GetStakingInfo(ctx context.Context, addr tezos.Address, id BlockID) (*StakingInfo, error) {
+	head, err := c.GetBlockHeader(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := c.GetBlockMetadata(ctx, head.Hash)
+	if err != nil {
+		return nil, err
+	}
+	b := Block{Header: *head, Metadata: *meta}
+	p, err := c.GetParams(ctx, head.Hash)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := c.GetContract(ctx, addr, head.Hash)
+	if err != nil {
+		return nil, err
+	}
+	staked, err := c.GetStakedBalance(ctx, addr, head.Hash)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.GetUnstakeRequests(ctx, addr, head.Hash)
+	if err != nil {
+		return nil, err
+	}
+	info := newStakingInfo(addr, b.GetLevelInfo(), b.GetTimestamp(), p, req)
+	info.Delegate = contract.Delegate
+	info.Spendable = contract.Balance
+	info.Staked = staked
+	if info.Delegate.IsValid() {
+		if info.Params, err = c.GetDelegateStakingParams(ctx, info.Delegate, head.Hash); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// newStakingInfo sorts unstake requests into finalizable and pending funds
+// and estimates unlock times from the current block.
+func newStakingInfo(addr tezos.Address, level LevelInfo, now time.Time, p *tezos.Params, req *UnstakeRequests) *StakingInfo {
+	info := &StakingInfo{
+		Address: addr,
+		Height:  level.Level,
+		Cycle:   level.Cycle,
+		Pending: make([]UnstakeUnlock, 0),
+	}
+	for _, v := range req.Finalizable {
+		info.Finalizable += v.Amount
+	}
+	for _, v := range req.Unfinalized.Requests {
+		unlock := UnstakeUnlockCycle(p, v.Cycle)
+		if unlock <= info.Cycle {
+			// the node still considers funds frozen
+			unlock = info.Cycle + 1
+		}
+		blocks := (unlock-info.Cycle)*p.BlocksPerCycle - level.CyclePosition
+		info.Unstaked += v.Amount
+		info.Pending = append(info.Pending, UnstakeUnlock{
+			Cycle:  v.Cycle,
+			Unlock: unlock,
+			Amount: v.Amount,
+			Time:   now.Add(time.Duration(blocks) * p.MinimalBlockDelay),
+		})
+	}
+	sort.SliceStable(info.Pending, func(i, j int) bool {
+		a, b := info.Pending[i], info.Pending[j]
+		return a.Unlock < b.Unlock || (a.Unlock == b.Unlock && a.Cycle < b.Cycle)
+	})
+	return info
+}
+
+// FinalizeUnstake sends a finalize_unstake operation for the sender in opts
+// when unstaked funds are ready and returns ErrNothingToFinalize otherwise.
+func (c *Client) FinalizeUnstake(ctx context.Context, opts *CallOptions) (*Receipt, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	_, addr, _, err := c.sender(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	info, err := c.GetStakingInfo(ctx, addr, Head)
+	if err != nil {
+		return nil, err
+	}
+	op := info.FinalizeOp()
+	if op == nil {
+		return nil, ErrNothingToFinalize
+	}
+	return c.Send(ctx, op, opts)
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+const (
+	stakerBaker = "tz1ci1ARnm8JoYV16Hbe4FoxX17yFEAQVytg"
+	stakerUser  = "tz1U4yF2Bkd7hV2JHW2styAWPif12TUCyS2S"
+)
+
+func TestStakingInfo(t *testing.T) {
+	raw := `{"finalizable":[
+		{"delegate":"` + stakerBaker + `","cycle":90,"amount":"1000"},
+		{"delegate":"` + stakerBaker + `","cycle":91,"amount":"500"}
+	],"unfinalized":{"delegate":"` + stakerBaker + `","requests":[
+		{"cycle":99,"amount":"3000"},
+		{"cycle":97,"amount":"2000"},
+		{"cycle":95,"amount":"100"}
+	]}}`
+	req := &UnstakeRequests{}
+	require.NoError(t, json.Unmarshal([]byte(raw), req))
+	assert.Equal(t, stakerBaker, req.Unfinalized.Delegate.String())
+
+	p := &tezos.Params{
+		ConsensusRightsDelay: 2,
+		BlocksPerCycle:       100,
+		MinimalBlockDelay:    6 * time.Second,
+	}
+	assert.Equal(t, int64(104), UnstakeUnlockCycle(p, 100))
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	addr := tezos.MustParseAddress(stakerUser)
+	info := newStakingInfo(addr, LevelInfo{Level: 10_040, Cycle: 100, CyclePosition: 40}, now, p, req)
+	assert.Equal(t, int64(100), info.Cycle)
+	assert.Equal(t, int64(1500), info.Finalizable)
+	assert.Equal(t, int64(5100), info.Unstaked)
+	assert.True(t, info.CanFinalize())
+
+	type row struct {
+		Cycle, Unlock, Amount int64
+		Time                  time.Time
+	}
+	var rows []row
+	for _, v := range info.Pending {
+		rows = append(rows, row{v.Cycle, v.Unlock, v.Amount, v.Time})
+	}
+	assert.Equal(t, []row{
+		{95, 101, 100, now.Add(60 * 6 * time.Second)},
+		{97, 101, 2000, now.Add(60 * 6 * time.Second)},
+		{99, 103, 3000, now.Add(260 * 6 * time.Second)},
+	}, rows)
+	assert.Equal(t, int64(95), info.NextUnlock().Cycle)
+
+	op := info.FinalizeOp()
+	require.NotNil(t, op)
+	require.Len(t, op.Contents, 1)
+	assert.Equal(t, tezos.OpTypeTransaction, op.Contents[0].Kind())
+	assert.Equal(t, addr, op.Source)
+
+	// nothing to finalize
+	info = newStakingInfo(addr, LevelInfo{Cycle: 100}, now, p, &UnstakeRequests{})
+	assert.False(t, info.CanFinalize())
+	assert.Nil(t, info.FinalizeOp())
+	assert.Nil(t, info.NextUnlock())
+}
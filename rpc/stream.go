@@ -0,0 +1,425 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trilitech/tzgo/tezos"
+)
+
+var (
+	// ErrReorgTooDeep is returned by a block stream when a reorg reaches
+	// below the oldest block it remembers.
+	ErrReorgTooDeep = errors.New("reorg deeper than stream history")
+
+	errStreamReorg = errors.New("stream reorg")
+)
+
+// StreamCheckpoint identifies the last block an indexer has processed.
+type StreamCheckpoint struct {
+	Level int64           `json:"level"`
+	Hash  tezos.BlockHash `json:"hash"`
+}
+
+// StreamOptions configure a block stream.
+type StreamOptions struct {
+	From         int64             // first level to stream, zero starts at the current head
+	To           int64             // last level to stream, zero follows head forever
+	Checkpoint   *StreamCheckpoint // resume after this block, overrides From
+	Workers      int               // parallel block fetchers (default 4)
+	Buffer       int               // max blocks prefetched ahead of delivery (default 2x workers)
+	Depth        int               // number of recent blocks kept for reorg detection (default 64)
+	PollInterval time.Duration     // head polling interval when monitoring is unsupported
+}
+
+// BlockStreamEvent is a block delivered in level order or a rollback. On
+// rollback all previously delivered blocks above Level were orphaned and
+// must be reverted. Level and Hash always identify the new chain tip as
+// seen by the consumer and can be stored as checkpoint.
+type BlockStreamEvent struct {
+	Block    *Block          // the next block, nil on rollback
+	Rollback bool            // blocks above Level were orphaned
+	Level    int64           // block level or rollback target level
+	Hash     tezos.BlockHash // block hash or rollback target hash
+	Live     bool            // block was fetched after catching up with head
+}
+
+// Checkpoint returns a checkpoint to resume streaming after this event.
+func (e *BlockStreamEvent) Checkpoint() StreamCheckpoint {
+	return StreamCheckpoint{Level: e.Level, Hash: e.Hash}
+}
+
+// BlockStream fetches blocks with parallel workers and delivers them in
+// level order. It first catches up to the current head, then follows new
+// heads from MonitorBlockHeader (or by polling when unsupported) and emits
+// rollbacks when the chain reorganizes. Delivery blocks until the consumer
+// reads the next event, which bounds prefetching to Buffer blocks.
+type BlockStream struct {
+	c      *Client
+	opts   StreamOptions
+	events chan *BlockStreamEvent
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+
+	next   int64                     // next level to deliver
+	last   tezos.BlockHash           // hash of the last delivered block
+	window map[int64]tezos.BlockHash // recent delivered blocks by level
+	base   int64                     // lowest level in window
+}
+
+// StreamBlocks starts a block stream. Read events from Events until the
+// channel is closed, then check Err.
+func (c *Client) StreamBlocks(ctx context.Context, opts StreamOptions) *BlockStream {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = 2 * opts.Workers
+	}
+	if opts.Depth <= 0 {
+		opts.Depth = DefaultObserverDepth
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = tezos.DefaultParams.MinimalBlockDelay
+		if c.Params != nil {
+			opts.PollInterval = c.Params.MinimalBlockDelay
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s := &BlockStream{
+		c:      c,
+		opts:   opts,
+		events: make(chan *BlockStreamEvent),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		window: make(map[int64]tezos.BlockHash),
+	}
+	go s.run()
+	return s
+}
+
+// Events returns the ordered event channel. It is closed when the stream
+// reached To, failed or was closed.
+func (s *BlockStream) Events() <-chan *BlockStreamEvent {
+	return s.events
+}
+
+// Err returns the error that ended the stream after Events was closed.
+func (s *BlockStream) Err() error {
+	<-s.done
+	return s.err
+}
+
+// Close stops the stream and waits for all workers to exit.
+func (s *BlockStream) Close() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *BlockStream) run() {
+	defer close(s.done)
+	defer close(s.events)
+	err := s.loop()
+	if err != nil && !errors.Is(err, context.Canceled) {
+		s.err = err
+	}
+	s.cancel()
+}
+
+func (s *BlockStream) loop() error {
+	if err := s.init(); err != nil {
+		return err
+	}
+
+	// catch up to head
+	for {
+		if s.finished() {
+			return nil
+		}
+		head, err := s.c.GetTipHeader(s.ctx)
+		if err != nil {
+			return err
+		}
+		target := s.target(head.Level)
+		if s.next > target {
+			break
+		}
+		if err := s.fetchRange(s.next, target, false); err != nil && err != errStreamReorg {
+			return err
+		}
+	}
+
+	// follow new heads
+	heads := make(chan *BlockHeaderLogEntry)
+	go s.watch(heads)
+	for {
+		var head *BlockHeaderLogEntry
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case head = <-heads:
+		}
+		if head.Level < s.next {
+			if hash, ok := s.window[head.Level]; ok && hash.Equal(head.Hash) {
+				continue
+			}
+			// new head replaces delivered blocks
+			if err := s.reorg(head.Hash); err != nil {
+				return err
+			}
+		}
+		for target := s.target(head.Level); s.next <= target; {
+			if err := s.fetchRange(s.next, target, true); err != nil && err != errStreamReorg {
+				return err
+			}
+		}
+		if s.finished() {
+			return nil
+		}
+	}
+}
+
+// init determines the first level to stream and checks the checkpoint.
+func (s *BlockStream) init() error {
+	cp := s.opts.Checkpoint
+	switch {
+	case cp != nil:
+		h, err := s.c.GetBlockHeader(s.ctx, BlockLevel(cp.Level))
+		if err != nil {
+			return err
+		}
+		if h.Hash.Equal(cp.Hash) {
+			s.remember(cp.Level, cp.Hash)
+			return nil
+		}
+		// the checkpoint was orphaned, blocks FinalityDepth below were final
+		level := cp.Level - FinalityDepth
+		if level < 0 {
+			level = 0
+		}
+		h, err = s.c.GetBlockHeader(s.ctx, BlockLevel(level))
+		if err != nil {
+			return err
+		}
+		s.remember(level, h.Hash)
+		return s.send(&BlockStreamEvent{Rollback: true, Level: level, Hash: h.Hash})
+	case s.opts.From > 0:
+		s.next = s.opts.From
+	default:
+		head, err := s.c.GetTipHeader(s.ctx)
+		if err != nil {
+			return err
+		}
+		s.next = head.Level
+	}
+	return nil
+}
+
+// finished returns true when the last requested level was delivered.
+func (s *BlockStream) finished() bool {
+	return s.opts.To > 0 && s.next > s.opts.To
+}
+
+// target caps level at To.
+func (s *BlockStream) target(level int64) int64 {
+	if s.opts.To > 0 && level > s.opts.To {
+		return s.opts.To
+	}
+	return level
+}
+
+type streamFetch struct {
+	level int64
+	res   chan *Block
+	err   chan error
+}
+
+// fetchRange fetches levels from..to with parallel workers and delivers
+// them in order. It returns errStreamReorg after a rollback.
+func (s *BlockStream) fetchRange(from, to int64, live bool) error {
+	ctx, cancel := context.WithCancel(s.ctx)
+	var (
+		jobs  = make(chan *streamFetch)
+		queue = make(chan *streamFetch, s.opts.Buffer)
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < s.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				b, err := s.c.GetBlock(ctx, BlockLevel(job.level))
+				if err != nil {
+					job.err <- err
+					continue
+				}
+				job.res <- b
+			}
+		}()
+	}
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// schedule in level order, queue capacity bounds prefetching
+	go func() {
+		defer close(jobs)
+		defer close(queue)
+		for level := from; level <= to; level++ {
+			job := &streamFetch{level, make(chan *Block, 1), make(chan error, 1)}
+			select {
+			case <-ctx.Done():
+				return
+			case queue <- job:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- job:
+			}
+		}
+	}()
+
+	for job := range queue {
+		var b *Block
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-job.err:
+			return fmt.Errorf("stream: block %d: %w", job.level, err)
+		case b = <-job.res:
+		}
+		if err := s.deliver(b, live); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// deliver checks that b extends the delivered chain and sends it to the
+// consumer. On mismatch it rolls back to the common ancestor instead.
+func (s *BlockStream) deliver(b *Block, live bool) error {
+	if s.last.IsValid() && !b.Header.Predecessor.Equal(s.last) {
+		if err := s.reorg(b.Header.Predecessor); err != nil {
+			return err
+		}
+		return errStreamReorg
+	}
+	if err := s.send(&BlockStreamEvent{Block: b, Level: b.GetLevel(), Hash: b.Hash, Live: live}); err != nil {
+		return err
+	}
+	s.remember(b.GetLevel(), b.Hash)
+	return nil
+}
+
+// reorg finds the common ancestor of block hash and the delivered chain and
+// emits a rollback to it.
+func (s *BlockStream) reorg(hash tezos.BlockHash) error {
+	for {
+		h, err := s.c.GetBlockHeader(s.ctx, hash)
+		if err != nil {
+			return err
+		}
+		if known, ok := s.window[h.Level]; ok && known.Equal(h.Hash) {
+			s.c.Log.Debugf("stream: reorg, rolling back to %d %s", h.Level, h.Hash)
+			for l := h.Level + 1; l < s.next; l++ {
+				delete(s.window, l)
+			}
+			s.next = h.Level + 1
+			s.last = h.Hash
+			return s.send(&BlockStreamEvent{Rollback: true, Level: h.Level, Hash: h.Hash})
+		}
+		if h.Level <= s.base {
+			return ErrReorgTooDeep
+		}
+		hash = h.Predecessor
+	}
+}
+
+// remember records a delivered block and trims old history.
+func (s *BlockStream) remember(level int64, hash tezos.BlockHash) {
+	s.window[level] = hash
+	s.last = hash
+	s.next = level + 1
+	if len(s.window) == 1 || level < s.base {
+		s.base = level
+	}
+	for ; level-s.base >= int64(s.opts.Depth); s.base++ {
+		delete(s.window, s.base)
+	}
+}
+
+func (s *BlockStream) send(ev *BlockStreamEvent) error {
+	select {
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	case s.events <- ev:
+		return nil
+	}
+}
+
+// watch forwards new heads from the node's head monitor and falls back to
+// polling when monitoring is unsupported.
+func (s *BlockStream) watch(heads chan<- *BlockHeaderLogEntry) {
+	useEvents := true
+	for {
+		var head *BlockHeaderLogEntry
+		if useEvents {
+			mon := NewBlockHeaderMonitor()
+			if err := s.c.MonitorBlockHeader(s.ctx, mon); err != nil {
+				mon.Close()
+				if ErrorStatus(err) == 404 {
+					s.c.Log.Debug("stream: event mode unsupported, falling back to poll mode.")
+					useEvents = false
+					continue
+				}
+				select {
+				case <-s.ctx.Done():
+					return
+				case <-time.After(5 * time.Second):
+				}
+				continue
+			}
+			for {
+				h, err := mon.Recv(s.ctx)
+				if err != nil {
+					mon.Close()
+					break
+				}
+				select {
+				case <-s.ctx.Done():
+					mon.Close()
+					return
+				case heads <- h:
+				}
+			}
+			continue
+		}
+
+		// poll mode
+		h, err := s.c.GetTipHeader(s.ctx)
+		if err == nil {
+			head = h.LogEntry()
+			select {
+			case <-s.ctx.Done():
+				return
+			case heads <- head:
+			}
+		}
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(s.opts.PollInterval):
+		}
+	}
+}
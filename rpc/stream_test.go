@@ -0,0 +1,113 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func nextStreamEvent(t *testing.T, s *BlockStream) *BlockStreamEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-s.Events():
+		if !ok {
+			t.Fatalf("stream closed: %v", s.Err())
+		}
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for stream event")
+		return nil
+	}
+}
+
+func TestBlockStream(t *testing.T) {
+	chain, cli := newTestChain(t)
+	blocks := []*testBlock{chain.add(nil, 1)}
+	for i := 2; i <= 10; i++ {
+		blocks = append(blocks, chain.add(blocks[len(blocks)-1], byte(i)))
+	}
+
+	s := cli.StreamBlocks(context.Background(), StreamOptions{
+		From:         3,
+		Workers:      3,
+		Buffer:       2,
+		PollInterval: 5 * time.Millisecond,
+	})
+	defer s.Close()
+
+	// catch up in level order
+	for level := int64(3); level <= 10; level++ {
+		ev := nextStreamEvent(t, s)
+		require.False(t, ev.Rollback)
+		require.Equal(t, level, ev.Level)
+		assert.Equal(t, blocks[level-1].hash, ev.Hash)
+		assert.Equal(t, blocks[level-1].hash, ev.Block.Hash)
+		assert.False(t, ev.Live)
+	}
+
+	// follow head
+	a11 := chain.add(blocks[9], 11)
+	ev := nextStreamEvent(t, s)
+	assert.Equal(t, a11.hash, ev.Hash)
+
+	// reorg at the same level
+	b11 := chain.add(blocks[9], 0x21)
+	ev = nextStreamEvent(t, s)
+	require.True(t, ev.Rollback)
+	assert.Nil(t, ev.Block)
+	assert.Equal(t, StreamCheckpoint{Level: 10, Hash: blocks[9].hash}, ev.Checkpoint())
+	ev = nextStreamEvent(t, s)
+	assert.Equal(t, b11.hash, ev.Hash)
+	b12 := chain.add(b11, 0x22)
+	ev = nextStreamEvent(t, s)
+	assert.Equal(t, b12.hash, ev.Hash)
+	assert.True(t, ev.Live)
+
+	s.Close()
+	_, ok := <-s.Events()
+	assert.False(t, ok)
+	assert.NoError(t, s.Err())
+}
+
+func TestBlockStreamCheckpoint(t *testing.T) {
+	chain, cli := newTestChain(t)
+	blocks := []*testBlock{chain.add(nil, 1)}
+	for i := 2; i <= 10; i++ {
+		blocks = append(blocks, chain.add(blocks[len(blocks)-1], byte(i)))
+	}
+
+	// resume after a known block
+	s := cli.StreamBlocks(context.Background(), StreamOptions{
+		Checkpoint: &StreamCheckpoint{Level: 5, Hash: blocks[4].hash},
+		To:         6,
+	})
+	ev := nextStreamEvent(t, s)
+	assert.Equal(t, int64(6), ev.Level)
+	_, ok := <-s.Events()
+	assert.False(t, ok)
+	require.NoError(t, s.Err())
+
+	// resume after an orphaned block rolls back to a final block
+	orphan := tezos.NewBlockHash(append(make([]byte, 31), 0xff))
+	s = cli.StreamBlocks(context.Background(), StreamOptions{
+		Checkpoint: &StreamCheckpoint{Level: 5, Hash: orphan},
+		To:         7,
+	})
+	ev = nextStreamEvent(t, s)
+	require.True(t, ev.Rollback)
+	assert.Equal(t, StreamCheckpoint{Level: 3, Hash: blocks[2].hash}, ev.Checkpoint())
+	for level := int64(4); level <= 7; level++ {
+		ev = nextStreamEvent(t, s)
+		assert.Equal(t, level, ev.Level)
+	}
+	_, ok = <-s.Events()
+	assert.False(t, ok)
+	require.NoError(t, s.Err())
+}
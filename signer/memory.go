@@ -11,7 +11,10 @@ import (
 	"github.com/trilitech/tzgo/tezos"
 )
 
-var ErrAddressMismatch = errors.New("signer: address mismatch")
+var (
+	ErrAddressMismatch = errors.New("signer: address mismatch")
+	ErrNotPacked       = errors.New("signer: data is not packed")
+)
 
 type MemorySigner struct {
 	key tezos.PrivateKey
@@ -39,12 +42,18 @@ func (s MemorySigner) SignMessage(_ context.Context, addr tezos.Address, msg str
 	if !s.key.Address().Equal(addr) {
 		return tezos.InvalidSignature, ErrAddressMismatch
 	}
-	op := codec.NewOp().
-		WithBranch(tezos.ZeroBlockHash).
-		WithContents(&codec.FailingNoop{
-			Arbitrary: msg,
-		})
-	digest := tezos.Digest(op.Bytes())
+	digest := tezos.Digest(MessageBytes(msg))
+	return s.key.Sign(digest[:])
+}
+
+func (s MemorySigner) SignPacked(_ context.Context, addr tezos.Address, data []byte) (tezos.Signature, error) {
+	if !s.key.Address().Equal(addr) {
+		return tezos.InvalidSignature, ErrAddressMismatch
+	}
+	if len(data) == 0 || data[0] != 0x05 {
+		return tezos.InvalidSignature, ErrNotPacked
+	}
+	digest := tezos.Digest(data)
 	return s.key.Sign(digest[:])
 }
 
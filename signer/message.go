@@ -0,0 +1,223 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package signer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trilitech/tzgo/codec"
+	"github.com/trilitech/tzgo/micheline"
+	"github.com/trilitech/tzgo/tezos"
+)
+
+// SignedMessagePrefix is the conventional prefix of off-chain messages signed
+// through wallets (e.g. via Beacon's MICHELINE signing type).
+const SignedMessagePrefix = "Tezos Signed Message:"
+
+var (
+	ErrInvalidSignature = errors.New("signer: invalid signature")
+	ErrMessageFormat    = errors.New("signer: invalid message format")
+	ErrMessageDomain    = errors.New("signer: message domain mismatch")
+	ErrMessageExpired   = errors.New("signer: message expired")
+	ErrMessageFuture    = errors.New("signer: message timestamp in the future")
+	ErrMessageReplay    = errors.New("signer: message replayed")
+)
+
+// MessageBytes returns the watermarked bytes signed by Signer.SignMessage,
+// i.e. a failing noop operation containing msg with zero branch hash.
+// Signatures over this operation can never be included on-chain.
+func MessageBytes(msg string) []byte {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteByte(codec.OperationWatermark)
+	buf.Write(tezos.ZeroBlockHash.Bytes())
+	_ = (&codec.FailingNoop{Arbitrary: msg}).EncodeBuffer(buf, tezos.DefaultParams)
+	return buf.Bytes()
+}
+
+// VerifyMessage checks a signature produced by Signer.SignMessage.
+func VerifyMessage(key tezos.Key, msg string, sig tezos.Signature) error {
+	digest := tezos.Digest(MessageBytes(msg))
+	return verify(key, digest[:], sig)
+}
+
+// SignPackedMessage signs msg in Micheline packed string format (0x05 prefix)
+// as wallets do for off-chain login and approval messages.
+func SignPackedMessage(ctx context.Context, s Signer, addr tezos.Address, msg string) (tezos.Signature, error) {
+	return SignPacked(ctx, s, addr, micheline.NewString(msg).Pack())
+}
+
+// VerifyPacked checks a signature over Micheline packed data.
+func VerifyPacked(key tezos.Key, data []byte, sig tezos.Signature) error {
+	if len(data) == 0 || data[0] != 0x05 {
+		return ErrNotPacked
+	}
+	digest := tezos.Digest(data)
+	return verify(key, digest[:], sig)
+}
+
+// VerifyPackedMessage checks a signature over msg in Micheline packed string format.
+func VerifyPackedMessage(key tezos.Key, msg string, sig tezos.Signature) error {
+	return VerifyPacked(key, micheline.NewString(msg).Pack(), sig)
+}
+
+// UnpackMessage decodes a Micheline packed string payload.
+func UnpackMessage(data []byte) (string, error) {
+	if len(data) == 0 || data[0] != 0x05 {
+		return "", ErrNotPacked
+	}
+	var p micheline.Prim
+	if err := p.UnmarshalBinary(data[1:]); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrMessageFormat, err)
+	}
+	if p.Type != micheline.PrimString {
+		return "", fmt.Errorf("%w: packed data is not a string", ErrMessageFormat)
+	}
+	return p.String, nil
+}
+
+func verify(key tezos.Key, digest []byte, sig tezos.Signature) error {
+	if !key.IsValid() || !sig.IsValid() {
+		return ErrInvalidSignature
+	}
+	if err := key.Verify(digest, sig); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return nil
+}
+
+// SignedMessage is an off-chain message following the wallet convention
+// `Tezos Signed Message: <domain> <timestamp> <text>` where timestamp
+// is in ISO-8601 format.
+type SignedMessage struct {
+	Domain    string
+	Timestamp time.Time
+	Text      string
+}
+
+func NewSignedMessage(domain, text string) SignedMessage {
+	return SignedMessage{
+		Domain:    domain,
+		Timestamp: time.Now().UTC(),
+		Text:      text,
+	}
+}
+
+func (m SignedMessage) String() string {
+	return strings.Join([]string{
+		SignedMessagePrefix,
+		m.Domain,
+		m.Timestamp.UTC().Format(time.RFC3339Nano),
+		m.Text,
+	}, " ")
+}
+
+// Bytes returns the Micheline packed string representation of the message.
+func (m SignedMessage) Bytes() []byte {
+	return micheline.NewString(m.String()).Pack()
+}
+
+// ParseSignedMessage parses a message string following the wallet convention.
+func ParseSignedMessage(s string) (SignedMessage, error) {
+	var m SignedMessage
+	if !strings.HasPrefix(s, SignedMessagePrefix+" ") {
+		return m, fmt.Errorf("%w: missing %q prefix", ErrMessageFormat, SignedMessagePrefix)
+	}
+	fields := strings.SplitN(strings.TrimPrefix(s, SignedMessagePrefix+" "), " ", 3)
+	if len(fields) < 3 {
+		return m, fmt.Errorf("%w: missing domain, timestamp or text", ErrMessageFormat)
+	}
+	ts, err := time.Parse(time.RFC3339Nano, fields[1])
+	if err != nil {
+		return m, fmt.Errorf("%w: invalid timestamp: %v", ErrMessageFormat, err)
+	}
+	m.Domain = fields[0]
+	m.Timestamp = ts
+	m.Text = fields[2]
+	return m, nil
+}
+
+// DecodeSignedMessage unpacks and parses a Micheline packed message.
+func DecodeSignedMessage(data []byte) (SignedMessage, error) {
+	s, err := UnpackMessage(data)
+	if err != nil {
+		return SignedMessage{}, err
+	}
+	return ParseSignedMessage(s)
+}
+
+// Sign signs the message in Micheline packed format.
+func (m SignedMessage) Sign(ctx context.Context, s Signer, addr tezos.Address) (tezos.Signature, error) {
+	return SignPacked(ctx, s, addr, m.Bytes())
+}
+
+// MessageVerifier checks signed wallet messages for a single domain and
+// protects against replay. Signatures are remembered until the message
+// would have expired anyway.
+type MessageVerifier struct {
+	Domain  string        // expected domain (dapp URL), empty to skip the check
+	MaxAge  time.Duration // max time since the message was signed
+	MaxSkew time.Duration // max clock skew for timestamps in the future
+
+	now  func() time.Time
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func NewMessageVerifier(domain string) *MessageVerifier {
+	return &MessageVerifier{
+		Domain:  domain,
+		MaxAge:  5 * time.Minute,
+		MaxSkew: 30 * time.Second,
+		now:     time.Now,
+		seen:    make(map[string]time.Time),
+	}
+}
+
+// Verify checks signature sig by key over Micheline packed message data and
+// returns the decoded message. Fails when the domain does not match, the
+// message is too old or too far in the future, or was seen before.
+func (v *MessageVerifier) Verify(key tezos.Key, data []byte, sig tezos.Signature) (*SignedMessage, error) {
+	if err := VerifyPacked(key, data, sig); err != nil {
+		return nil, err
+	}
+	m, err := DecodeSignedMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	if v.Domain != "" && m.Domain != v.Domain {
+		return nil, fmt.Errorf("%w: %q", ErrMessageDomain, m.Domain)
+	}
+	now := v.now()
+	if m.Timestamp.After(now.Add(v.MaxSkew)) {
+		return nil, ErrMessageFuture
+	}
+	expires := m.Timestamp.Add(v.MaxAge)
+	if v.MaxAge > 0 && now.After(expires) {
+		return nil, ErrMessageExpired
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for k, exp := range v.seen {
+		if !exp.IsZero() && now.After(exp) {
+			delete(v.seen, k)
+		}
+	}
+	id := sig.String()
+	if _, ok := v.seen[id]; ok {
+		return nil, ErrMessageReplay
+	}
+	if v.MaxAge > 0 {
+		v.seen[id] = expires
+	} else {
+		v.seen[id] = time.Time{}
+	}
+	return &m, nil
+}
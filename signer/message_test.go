@@ -0,0 +1,81 @@
+// Copyright (c) 2026 TriliTech Ltd.
+// Author: tzstats@trili.tech
+
+package signer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/trilitech/tzgo/tezos"
+)
+
+func TestMessageSignVerify(t *testing.T) {
+	ctx := context.Background()
+	sk, err := tezos.GenerateKey(tezos.KeyTypeEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewFromKey(sk)
+	addr := sk.Address()
+
+	// failing noop messages
+	sig, err := s.SignMessage(ctx, addr, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyMessage(sk.Public(), "hello", sig); err != nil {
+		t.Errorf("verify failing noop message: %v", err)
+	}
+	if err := VerifyMessage(sk.Public(), "hello!", sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected invalid signature, got %v", err)
+	}
+
+	// packed wallet messages
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	msg := SignedMessage{Domain: "https://example.com", Timestamp: now, Text: "login with nonce 42"}
+	if got, want := msg.String(), "Tezos Signed Message: https://example.com 2026-01-02T03:04:05Z login with nonce 42"; got != want {
+		t.Fatalf("message format mismatch\n got=%s\nwant=%s", got, want)
+	}
+	if got, err := DecodeSignedMessage(msg.Bytes()); err != nil || got != msg {
+		t.Fatalf("decode message: %v %#v", err, got)
+	}
+	sig, err = msg.Sign(ctx, s, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPackedMessage(sk.Public(), msg.String(), sig); err != nil {
+		t.Errorf("verify packed message: %v", err)
+	}
+
+	// signers without packed data support
+	wrapped := struct{ Signer }{s}
+	if _, err := msg.Sign(ctx, wrapped, addr); !errors.Is(err, ErrPackedNotSupported) {
+		t.Errorf("expected packed signing to be unsupported, got %v", err)
+	}
+
+	v := NewMessageVerifier("https://example.com")
+	v.now = func() time.Time { return now.Add(time.Minute) }
+	if _, err := v.Verify(sk.Public(), msg.Bytes(), sig); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if _, err := v.Verify(sk.Public(), msg.Bytes(), sig); !errors.Is(err, ErrMessageReplay) {
+		t.Errorf("expected replay error, got %v", err)
+	}
+	v.now = func() time.Time { return now.Add(time.Hour) }
+	if _, err := v.Verify(sk.Public(), msg.Bytes(), sig); !errors.Is(err, ErrMessageExpired) {
+		t.Errorf("expected expired error, got %v", err)
+	}
+	v = NewMessageVerifier("https://other.com")
+	v.now = func() time.Time { return now }
+	if _, err := v.Verify(sk.Public(), msg.Bytes(), sig); !errors.Is(err, ErrMessageDomain) {
+		t.Errorf("expected domain error, got %v", err)
+	}
+
+	// raw operation bytes are never signed as packed data
+	if _, err := s.SignPacked(ctx, addr, []byte{0x03, 0x00}); !errors.Is(err, ErrNotPacked) {
+		t.Errorf("expected not packed error, got %v", err)
+	}
+}
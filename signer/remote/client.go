@@ -13,7 +13,10 @@ import (
 	"github.com/trilitech/tzgo/tezos"
 )
 
-var _ signer.Signer = (*RemoteSigner)(nil)
+var (
+	_ signer.Signer       = (*RemoteSigner)(nil)
+	_ signer.PackedSigner = (*RemoteSigner)(nil)
+)
 
 type RemoteSigner struct {
 	c     *rpc.Client
@@ -78,12 +81,29 @@ func (s RemoteSigner) GetKey(ctx context.Context, address tezos.Address) (tezos.
 // Note that most remote signers for Tezos do not support signing of operation kinds other
 // than baking related operations.
 func (s RemoteSigner) SignMessage(ctx context.Context, address tezos.Address, msg string) (tezos.Signature, error) {
-	op := codec.NewOp().
-		WithBranch(tezos.ZeroBlockHash).
-		WithContents(&codec.FailingNoop{
-			Arbitrary: msg,
-		})
-	return s.SignOperation(ctx, address, op)
+	type response struct {
+		Sig tezos.Signature `json:"signature"`
+	}
+	var resp response
+	err := s.c.Post(ctx, "/keys/"+address.String(), tezos.HexBytes(signer.MessageBytes(msg)), &resp)
+	return resp.Sig, err
+}
+
+// SignPacked signs Micheline packed data for address using the configured remote
+// signer's REST API. Data must start with the 0x05 magic byte.
+//
+// Note that remote signers may reject signing of Michelson data unless configured
+// to allow it.
+func (s RemoteSigner) SignPacked(ctx context.Context, address tezos.Address, data []byte) (tezos.Signature, error) {
+	if len(data) == 0 || data[0] != 0x05 {
+		return tezos.InvalidSignature, signer.ErrNotPacked
+	}
+	type response struct {
+		Sig tezos.Signature `json:"signature"`
+	}
+	var resp response
+	err := s.c.Post(ctx, "/keys/"+address.String(), tezos.HexBytes(data), &resp)
+	return resp.Sig, err
 }
 
 // SignOperation signs operation op for address using the configured remote signer's
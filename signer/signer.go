@@ -5,6 +5,7 @@ package signer
 
 import (
 	"context"
+	"errors"
 
 	"github.com/trilitech/tzgo/codec"
 	"github.com/trilitech/tzgo/tezos"
@@ -26,3 +27,23 @@ type Signer interface {
 	// Sign a block header.
 	SignBlock(context.Context, tezos.Address, *codec.BlockHeader) (tezos.Signature, error)
 }
+
+var ErrPackedNotSupported = errors.New("signer: signing packed data is not supported")
+
+// PackedSigner is an optional interface for signers that can sign Micheline
+// packed data (0x05 prefixed bytes) such as multisig payloads and permits.
+type PackedSigner interface {
+	SignPacked(context.Context, tezos.Address, []byte) (tezos.Signature, error)
+}
+
+// SignPacked signs packed data with s when it implements PackedSigner.
+// Message signatures cover a failing noop wrapper and cannot be used in
+// place of a packed data signature, so other signers return
+// ErrPackedNotSupported.
+func SignPacked(ctx context.Context, s Signer, addr tezos.Address, data []byte) (tezos.Signature, error) {
+	ps, ok := s.(PackedSigner)
+	if !ok {
+		return tezos.InvalidSignature, ErrPackedNotSupported
+	}
+	return ps.SignPacked(ctx, addr, data)
+}